@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// AccessConfig restricts which client addresses may use which API key
+type AccessConfig struct {
+	// APIKeys lists per-key client address allowlists
+	APIKeys []APIKeyAccess `json:"apiKeys,omitempty"`
+	// AllowedCIDRs applies to requests whose API key has no entry in APIKeys (empty = any address)
+	AllowedCIDRs []string `json:"allowedCidrs,omitempty"`
+	// TrustedProxies are proxy ranges whose X-Forwarded-For header is trusted for the client address
+	TrustedProxies []string `json:"trustedProxies,omitempty"`
+
+	defaultNets []*net.IPNet
+}
+
+// APIKeyAccess is the allowlist of one API key. The key is referenced by its
+// SHA-256 hex digest so the configuration file never contains the key itself.
+type APIKeyAccess struct {
+	Name         string   `json:"name"`
+	KeySHA256    string   `json:"keySha256"`
+	AllowedCIDRs []string `json:"allowedCidrs"`
+
+	nets []*net.IPNet
+}
+
+// validate parses every CIDR so misconfiguration fails at startup
+func (a *AccessConfig) validate() error {
+	var err error
+	if a.defaultNets, err = parseCIDRs(a.AllowedCIDRs); err != nil {
+		return fmt.Errorf("access.allowedCidrs: %w", err)
+	}
+	if _, err = parseCIDRs(a.TrustedProxies); err != nil {
+		return fmt.Errorf("access.trustedProxies: %w", err)
+	}
+	for i := range a.APIKeys {
+		key := &a.APIKeys[i]
+		key.KeySHA256 = strings.ToLower(key.KeySHA256)
+		if len(key.KeySHA256) != sha256.Size*2 {
+			return fmt.Errorf("access.apiKeys[%d] (%s): keySha256 must be a hex SHA-256 digest", i, key.Name)
+		}
+		if key.nets, err = parseCIDRs(key.AllowedCIDRs); err != nil {
+			return fmt.Errorf("access.apiKeys[%d] (%s): %w", i, key.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseCIDRs parses CIDRs and bare IP addresses (treated as single-host ranges)
+func parseCIDRs(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", v)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", v)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// allowedNets returns the allowlist applying to the given API key (nil = any address)
+func (a *AccessConfig) allowedNets(apiKey string) []*net.IPNet {
+	if apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		digest := hex.EncodeToString(sum[:])
+		for _, key := range a.APIKeys {
+			if key.KeySHA256 == digest {
+				return key.nets
+			}
+		}
+	}
+	return a.defaultNets
+}
+
+// presentedAPIKey returns the API key of the request, sent either as X-API-Key
+// or as an Authorization bearer token; empty for unauthenticated requests
+func presentedAPIKey(c echo.Context) string {
+	if key := c.Request().Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+}
+
+// Middleware rejects requests whose client address is not allowed for the presented API key
+func (a *AccessConfig) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			nets := a.allowedNets(presentedAPIKey(c))
+			if len(nets) == 0 {
+				return next(c)
+			}
+			ip := net.ParseIP(c.RealIP())
+			for _, n := range nets {
+				if ip != nil && n.Contains(ip) {
+					return next(c)
+				}
+			}
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "client address not allowed for this API key"})
+		}
+	}
+}
+
+// IPExtractor determines the client address, trusting X-Forwarded-For only from configured proxies
+func (a *AccessConfig) IPExtractor() echo.IPExtractor {
+	if len(a.TrustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	nets, _ := parseCIDRs(a.TrustedProxies)
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, n := range nets {
+		options = append(options, echo.TrustIPRange(n))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// corsMiddleware builds the CORS policy from the environment. Without
+// INFISICAL_CORS_ALLOWED_ORIGINS no CORS headers are sent, so browsers deny
+// every cross-origin request.
+//
+//	INFISICAL_CORS_ALLOWED_ORIGINS    comma-separated origins (default: none)
+//	INFISICAL_CORS_ALLOWED_METHODS    default: GET,POST,PUT,PATCH,DELETE
+//	INFISICAL_CORS_ALLOWED_HEADERS    default: Content-Type,X-API-Key,Authorization
+//	INFISICAL_CORS_ALLOW_CREDENTIALS  default: false
+func corsMiddleware() (echo.MiddlewareFunc, bool) {
+	origins := envList("INFISICAL_CORS_ALLOWED_ORIGINS", nil)
+	if len(origins) == 0 {
+		return nil, false
+	}
+	credentials, _ := strconv.ParseBool(os.Getenv("INFISICAL_CORS_ALLOW_CREDENTIALS"))
+	return middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: origins,
+		AllowMethods: envList("INFISICAL_CORS_ALLOWED_METHODS", []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		}),
+		AllowHeaders: envList("INFISICAL_CORS_ALLOWED_HEADERS", []string{
+			echo.HeaderContentType, "X-API-Key", echo.HeaderAuthorization,
+		}),
+		AllowCredentials: credentials,
+	}), true
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAccessConfig_PerKeyAllowlist(t *testing.T) {
+	access := AccessConfig{
+		APIKeys: []APIKeyAccess{
+			{Name: "scheduler", KeySHA256: sha256Hex("scheduler-key"), AllowedCIDRs: []string{"10.0.0.0/8"}},
+		},
+		AllowedCIDRs: []string{"192.168.1.5"},
+	}
+	if err := access.validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+
+	e := echo.New()
+	e.IPExtractor = access.IPExtractor()
+	e.GET("/v1/api/secrets/:key", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, access.Middleware())
+
+	cases := []struct {
+		apiKey     string
+		bearer     bool
+		remoteAddr string
+		want       int
+	}{
+		{"scheduler-key", false, "10.1.2.3:1234", http.StatusOK},
+		{"scheduler-key", false, "192.168.1.5:1234", http.StatusForbidden},
+		{"scheduler-key", true, "10.1.2.3:1234", http.StatusOK},
+		{"scheduler-key", true, "192.168.1.5:1234", http.StatusForbidden},
+		{"other-key", false, "192.168.1.5:1234", http.StatusOK},
+		{"other-key", false, "10.1.2.3:1234", http.StatusForbidden},
+		{"", false, "192.168.1.6:1234", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/v1/api/secrets/KEY", nil)
+		req.RemoteAddr = tc.remoteAddr
+		if tc.bearer {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tc.apiKey)
+		} else {
+			req.Header.Set("X-API-Key", tc.apiKey)
+		}
+		// Untrusted forwarding headers must not influence the client address
+		req.Header.Set("X-Forwarded-For", "10.9.9.9")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("key=%q bearer=%v addr=%s: expected %d, got %d", tc.apiKey, tc.bearer, tc.remoteAddr, tc.want, rec.Code)
+		}
+	}
+}
+
+func TestLoadServiceConfig_RejectsInvalidCIDR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"access":{"allowedCidrs":["10.0.0.0/33"]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadServiceConfig(path); err == nil {
+		t.Error("Expected error for invalid CIDR")
+	}
+}
+
+func TestCORS_DenyByDefault(t *testing.T) {
+	t.Setenv("INFISICAL_CORS_ALLOWED_ORIGINS", "")
+	if _, enabled := corsMiddleware(); enabled {
+		t.Error("CORS must be disabled when no origins are configured")
+	}
+
+	t.Setenv("INFISICAL_CORS_ALLOWED_ORIGINS", "https://console.example.com")
+	cors, enabled := corsMiddleware()
+	if !enabled {
+		t.Fatal("CORS should be enabled for configured origins")
+	}
+
+	e := echo.New()
+	e.Use(cors)
+	e.GET("/v1/api/secrets/:key", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for origin, want := range map[string]string{
+		"https://console.example.com": "https://console.example.com",
+		"https://evil.example.com":    "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/api/secrets/KEY", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if got := rec.Header().Get(echo.HeaderAccessControlAllowOrigin); got != want {
+			t.Errorf("Origin %s: expected Allow-Origin %q, got %q", origin, want, got)
+		}
+	}
+
+	// Preflight for the PATCH folder route
+	req := httptest.NewRequest(http.MethodOptions, "/v1/api/folders/app", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://console.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPatch)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if got := rec.Header().Get(echo.HeaderAccessControlAllowMethods); !strings.Contains(got, http.MethodPatch) {
+		t.Errorf("Expected PATCH in the default Allow-Methods, got %q", got)
+	}
+}
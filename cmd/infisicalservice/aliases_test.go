@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAliases_Resolve(t *testing.T) {
+	aliases := AliasConfig{
+		"iqs-s3":      {ProjectID: "p-s3", SecretPath: "s3"},
+		"iqs-s3/prod": {ProjectID: "p-s3-prod", Environment: "prod", IncludeImports: true},
+		"basex":       {ProjectID: "p-basex", Environment: "dev", Identity: "dr"},
+	}
+	if err := aliases.validate(map[string]InfisicalInstance{"dr": {URL: "https://dr"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	exact, err := aliases.resolve("iqs-s3/prod")
+	if err != nil || exact.ProjectID != "p-s3-prod" || exact.SecretPath != "/" || !exact.IncludeImports {
+		t.Errorf("Expected the exact alias to win, got %+v, %v", exact, err)
+	}
+	staging, err := aliases.resolve("iqs-s3/staging")
+	if err != nil || staging.scope() != (secretScope{ProjectID: "p-s3", Environment: "staging", SecretPath: "/s3"}) {
+		t.Errorf("Expected iqs-s3 in staging, got %+v, %v", staging, err)
+	}
+	if _, err := aliases.resolve("iqs-s3"); err == nil {
+		t.Error("Expected an alias without environment to be rejected")
+	}
+	if _, err := aliases.resolve("unknown/prod"); err == nil {
+		t.Error("Expected an unknown alias to be rejected")
+	}
+	if basex, _ := aliases.resolve("basex"); basex.Identity != "dr" {
+		t.Errorf("Expected the alias identity to be kept, got %+v", basex)
+	}
+
+	if err := (AliasConfig{"x": {ProjectID: "p", Identity: "missing"}}).validate(nil); err == nil {
+		t.Error("Expected an unknown identity to be rejected")
+	}
+	if err := (AliasConfig{"x/": {ProjectID: "p"}}).validate(nil); err == nil {
+		t.Error("Expected a name ending in '/' to be rejected")
+	}
+}
+
+func TestAliases_TargetForms(t *testing.T) {
+	e := echo.New()
+	for body, want := range map[string]string{
+		`{"@type":"RetrieveAction","target":"iqs-s3/prod"}`:                          "iqs-s3/prod",
+		`{"@type":"RetrieveAction","target":{"@type":"EntryPoint","alias":"basex"}}`: "basex",
+		`{"@type":"RetrieveAction","target":{"actionPlatform":"p"}}`:                 "",
+	} {
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+		c.Set(rawActionContextKey, []byte(body))
+		if name, ok := targetAlias(c); name != want || ok != (want != "") {
+			t.Errorf("%s: expected alias %q, got %q", body, want, name)
+		}
+	}
+}
+
+func TestAliases_StringTargetEndToEnd(t *testing.T) {
+	saved := serviceConfig
+	defer func() { serviceConfig = saved }()
+	serviceConfig = &ServiceConfig{Aliases: AliasConfig{"iqs-s3": {ProjectID: "p-s3", SecretPath: "/"}}}
+
+	backend := newFakeBackend()
+	backend.set(secretScope{ProjectID: "p-s3", Environment: "prod", SecretPath: "/"}, "S3_BUCKET", "prod-bucket")
+	useFakeBackend(t, backend)
+
+	for _, target := range []interface{}{"iqs-s3/prod", map[string]interface{}{"@type": "EntryPoint", "alias": "iqs-s3/prod"}} {
+		rec := serveAction(t, map[string]interface{}{"@context": "https://schema.org", "@type": "RetrieveAction", "target": target})
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "prod-bucket") {
+			t.Errorf("target %v: expected the aliased secrets, got %d: %s", target, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// auditEvent records a security relevant event. Fields must never contain
+// secret values; callers pass identifiers, counts and key names only.
+// It is a variable so tests can capture emitted events.
+var auditEvent = func(event string, fields map[string]interface{}) {
+	record := map[string]interface{}{
+		"event": event,
+		"time":  time.Now().UTC().Format(time.RFC3339),
+	}
+	for k, v := range fields {
+		record[k] = v
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("AUDIT %s (failed to encode fields: %v)", event, err)
+		return
+	}
+	log.Printf("AUDIT %s", data)
+}
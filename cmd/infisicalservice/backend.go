@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/infisical/go-sdk/packages/models"
+)
+
+// secretScope identifies a folder of secrets in an Infisical project environment
+type secretScope struct {
+	ProjectID      string `json:"projectId"`
+	Environment    string `json:"environment"`
+	SecretPath     string `json:"secretPath"`
+	IncludeImports bool   `json:"includeImports,omitempty"`
+}
+
+// String formats the scope for log messages
+func (s secretScope) String() string {
+	return fmt.Sprintf("project=%s, env=%s, path=%s", s.ProjectID, s.Environment, s.SecretPath)
+}
+
+// secretVersion is one entry of a secret's version history
+type secretVersion struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Actor     string    `json:"actor,omitempty"`
+	Value     string    `json:"-"`
+}
+
+// secretImport is an import configured on a folder: the folder also serves
+// the secrets of the source environment and path
+type secretImport struct {
+	ID          string `json:"id"`
+	Environment string `json:"environment"`
+	SecretPath  string `json:"secretPath"`
+	// Position orders imports; the first import wins when keys collide
+	Position int `json:"position"`
+}
+
+// project is an Infisical project the identity can access
+type project struct {
+	ID           string               `json:"id"`
+	Name         string               `json:"name"`
+	Slug         string               `json:"slug,omitempty"`
+	Environments []projectEnvironment `json:"environments"`
+}
+
+// projectEnvironment is an environment of a project
+type projectEnvironment struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// errSecretNotFound is wrapped by RetrieveSecret errors for secrets that do not
+// exist, so callers can tell them from failed requests with errors.Is
+var errSecretNotFound = errors.New("secret not found")
+
+// secretBackend is the set of Infisical operations used by the action handlers
+type secretBackend interface {
+	// ListSecrets returns all secrets of a scope (including imports if requested)
+	ListSecrets(scope secretScope) ([]models.Secret, error)
+	// RetrieveSecret returns one secret; version 0 means the current version
+	RetrieveSecret(scope secretScope, key string, version int) (models.Secret, error)
+	// ListSecretVersions returns the version history of a secret, oldest first
+	ListSecretVersions(secret models.Secret) ([]secretVersion, error)
+	// CreateSecret creates a new secret; it fails if the key already exists
+	CreateSecret(scope secretScope, key, value string) (models.Secret, error)
+	// BatchCreateSecrets creates several new secrets in one request
+	BatchCreateSecrets(scope secretScope, entries []secretEntry) ([]models.Secret, error)
+	// UpdateSecret sets a new value for an existing secret
+	UpdateSecret(scope secretScope, key, value string) (models.Secret, error)
+	// UpdateSecretMetadata replaces the metadata key/value pairs of an existing secret
+	UpdateSecretMetadata(scope secretScope, key string, metadata []models.SecretMetadata) (models.Secret, error)
+	// DeleteSecret deletes a secret with its version history
+	DeleteSecret(scope secretScope, key string) error
+	// ListFolders returns the direct subfolders of scope.SecretPath
+	ListFolders(scope secretScope) ([]models.Folder, error)
+	// CreateFolder creates a subfolder of scope.SecretPath
+	CreateFolder(scope secretScope, name string) (models.Folder, error)
+	// RenameFolder renames a subfolder of scope.SecretPath
+	RenameFolder(scope secretScope, name, newName string) (models.Folder, error)
+	// DeleteFolder deletes a subfolder of scope.SecretPath with everything it contains
+	DeleteFolder(scope secretScope, name string) error
+	// ListSecretsBySource returns the secrets of a folder and, separately, the
+	// secrets of each of its imports in precedence order
+	ListSecretsBySource(scope secretScope) ([]models.Secret, []models.SecretImport, error)
+	// ListSecretImports returns the imports configured on scope.SecretPath
+	ListSecretImports(scope secretScope) ([]secretImport, error)
+	// CreateSecretImport imports the secrets of source.Environment and source.SecretPath into scope
+	CreateSecretImport(scope secretScope, source secretImport) (secretImport, error)
+	// DeleteSecretImport removes an import from scope
+	DeleteSecretImport(scope secretScope, id string) error
+	// ListProjects returns the projects the identity can access with their environments
+	ListProjects() ([]project, error)
+}
+
+// infisicalConnection holds what is needed to authenticate against an Infisical instance
+type infisicalConnection struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+}
+
+// infisicalConnectionFromEnv reads the connection settings from the environment
+func infisicalConnectionFromEnv() (infisicalConnection, error) {
+	conn := infisicalConnection{
+		URL:          os.Getenv("INFISICAL_API_URL"),
+		ClientID:     os.Getenv("INFISICAL_CLIENT_ID"),
+		ClientSecret: os.Getenv("INFISICAL_CLIENT_SECRET"),
+	}
+	if conn.URL == "" {
+		conn.URL = "https://app.infisical.com" // Default to Infisical Cloud
+	}
+	if conn.ClientID == "" || conn.ClientSecret == "" {
+		return conn, fmt.Errorf("INFISICAL_CLIENT_ID and INFISICAL_CLIENT_SECRET must be set")
+	}
+	return conn, nil
+}
+
+// instanceConnection returns the connection settings of a named instance; the
+// empty name is the service's own instance
+func instanceConnection(instances map[string]InfisicalInstance, name string) (infisicalConnection, error) {
+	if name == "" {
+		return infisicalConnectionFromEnv()
+	}
+	cfg, ok := instances[name]
+	if !ok {
+		return infisicalConnection{}, fmt.Errorf("unknown instance %q", name)
+	}
+	conn := infisicalConnection{
+		URL:          cfg.URL,
+		ClientID:     os.Getenv(cfg.ClientIDEnv),
+		ClientSecret: os.Getenv(cfg.ClientSecretEnv),
+	}
+	if conn.ClientID == "" || conn.ClientSecret == "" {
+		return conn, fmt.Errorf("instance %q: %s and %s must be set", name, cfg.ClientIDEnv, cfg.ClientSecretEnv)
+	}
+	return conn, nil
+}
+
+// connectBackend authenticates against Infisical and returns a backend.
+// It is a variable so tests can substitute a local fake backend.
+var connectBackend = func(conn infisicalConnection) (secretBackend, error) {
+	return newInfisicalBackend(conn)
+}
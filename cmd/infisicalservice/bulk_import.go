@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// Import outcomes per key
+const (
+	importCreated   = "created"
+	importUpdated   = "updated"
+	importUnchanged = "unchanged"
+	importSkipped   = "skipped"
+	importInvalid   = "invalid"
+	importFailed    = "failed"
+)
+
+// Import modes
+const (
+	importModeCreate = "create"
+	importModeUpsert = "upsert"
+)
+
+const (
+	// importBatchSize is the number of secrets created per batch request
+	importBatchSize = 100
+	// maxImportBytes limits the size of an import document
+	maxImportBytes = 1 << 20
+)
+
+// importOptions is the CreateAction property for bulk imports
+type importOptions struct {
+	// Format is dotenv, json or yaml; detected from the content when empty
+	Format  string `json:"format,omitempty"`
+	Content string `json:"content"`
+	// Mode is create (existing keys are skipped, default) or upsert (existing keys are updated)
+	Mode string `json:"mode,omitempty"`
+	// DryRun reports what would be written without writing anything
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// validate checks the options and applies defaults
+func (o *importOptions) validate() error {
+	switch o.Mode {
+	case "":
+		o.Mode = importModeCreate
+	case importModeCreate, importModeUpsert:
+	default:
+		return fmt.Errorf("import.mode must be create or upsert")
+	}
+	if strings.TrimSpace(o.Content) == "" {
+		return fmt.Errorf("import.content is required")
+	}
+	if len(o.Content) > maxImportBytes {
+		return fmt.Errorf("import.content exceeds %d bytes", maxImportBytes)
+	}
+	return nil
+}
+
+// importEntry reports what an import did (or would do) with one key. It never contains values.
+type importEntry struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Line    int    `json:"line,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// importSecrets writes parsed entries to a scope. New keys are created with
+// batch requests; existing keys are skipped or, in upsert mode, updated.
+func importSecrets(backend secretBackend, scope secretScope, entries []secretEntry, mode string, dryRun bool) ([]importEntry, error) {
+	existing, err := backend.ListSecrets(secretScope{ProjectID: scope.ProjectID, Environment: scope.Environment, SecretPath: scope.SecretPath})
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]string, len(existing))
+	for _, secret := range existing {
+		current[secret.SecretKey] = secret.SecretValue
+	}
+
+	report := make([]importEntry, len(entries))
+	seen := make(map[string]bool, len(entries))
+	var toCreate []int
+	for i, e := range entries {
+		report[i] = importEntry{Name: e.Key, Line: e.Line}
+		value, exists := current[e.Key]
+		writes := !exists || (mode == importModeUpsert && value != e.Value)
+		switch rejection := serviceConfig.Validation.rejection(scope, e.Key, e.Value); {
+		case !importKeyPattern.MatchString(e.Key):
+			report[i].Status, report[i].Reason = importInvalid, "key must start with a letter or underscore and contain only letters, digits, '_', '-' or '.'"
+		case seen[e.Key]:
+			report[i].Status, report[i].Reason = importInvalid, "duplicate key"
+		case writes && rejection != "":
+			report[i].Status, report[i].Reason = importInvalid, rejection
+		case !exists:
+			report[i].Status = importCreated
+			toCreate = append(toCreate, i)
+		case mode != importModeUpsert:
+			report[i].Status, report[i].Reason = importSkipped, "already exists"
+		case value == e.Value:
+			report[i].Status = importUnchanged
+		default:
+			report[i].Status = importUpdated
+			if !dryRun {
+				secret, err := backend.UpdateSecret(scope, e.Key, e.Value)
+				if err != nil {
+					report[i].Status, report[i].Reason = importFailed, err.Error()
+				} else {
+					report[i].Version = secret.Version
+				}
+			}
+		}
+		seen[e.Key] = true
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for start := 0; start < len(toCreate); start += importBatchSize {
+		chunk := toCreate[start:min(start+importBatchSize, len(toCreate))]
+		batch := make([]secretEntry, len(chunk))
+		for j, idx := range chunk {
+			batch[j] = entries[idx]
+		}
+		created, err := backend.BatchCreateSecrets(scope, batch)
+		if err != nil {
+			for _, idx := range chunk {
+				report[idx].Status, report[idx].Reason = importFailed, err.Error()
+			}
+			continue
+		}
+		versions := make(map[string]int, len(created))
+		for _, secret := range created {
+			versions[secret.SecretKey] = secret.Version
+		}
+		for _, idx := range chunk {
+			report[idx].Version = versions[entries[idx].Key]
+		}
+	}
+	return report, nil
+}
+
+// importResult builds the action result describing an import
+func importResult(entries []importEntry, mode string, dryRun bool) *semantic.SemanticResult {
+	summary := map[string]int{}
+	for _, e := range entries {
+		summary[e.Status]++
+	}
+	return &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"dryRun":  dryRun,
+			"mode":    mode,
+			"summary": summary,
+			"entries": entries,
+		},
+	}
+}
+
+// handleImport bulk-creates or upserts the secrets of a dotenv, JSON or YAML document
+func handleImport(c echo.Context, action *semantic.SemanticAction, opts *importOptions) error {
+	if err := opts.validate(); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid import options", err)
+	}
+	entries, err := parseImport(opts.Format, opts.Content)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to parse import document", err)
+	}
+
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+
+	report, err := importSecrets(backend, scope, entries, opts.Mode, opts.DryRun)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to import secrets", err)
+	}
+
+	result := importResult(report, opts.Mode, opts.DryRun)
+	summary := result.Value.(map[string]interface{})["summary"]
+	log.Printf("Import of %d keys (%s, mode=%s, dryRun=%v): %v", len(report), scope, opts.Mode, opts.DryRun, summary)
+	if !opts.DryRun {
+		auditEvent("secret.import", map[string]interface{}{
+			"projectId":   scope.ProjectID,
+			"environment": scope.Environment,
+			"secretPath":  scope.SecretPath,
+			"mode":        opts.Mode,
+			"keys":        len(report),
+			"summary":     summary,
+		})
+	}
+
+	action.Result = result
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// ImportSecretsRequest is the JSON body of the REST import endpoint
+type ImportSecretsRequest struct {
+	Format      string `json:"format,omitempty"`
+	Content     string `json:"content"`
+	Mode        string `json:"mode,omitempty"`
+	DryRun      bool   `json:"dryRun,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	ProjectID   string `json:"projectId,omitempty"`
+	SecretPath  string `json:"secretPath,omitempty"`
+}
+
+// importSecretsREST handles REST POST /v1/api/secrets/import. The body is
+// either an ImportSecretsRequest or, for any non-JSON content type, the raw
+// document with options in the query string.
+func importSecretsREST(c echo.Context) error {
+	var req ImportSecretsRequest
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+		}
+	} else {
+		body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxImportBytes+1))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+		}
+		dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun"))
+		req = ImportSecretsRequest{
+			Format:      c.QueryParam("format"),
+			Content:     string(body),
+			Mode:        c.QueryParam("mode"),
+			DryRun:      dryRun,
+			Environment: c.QueryParam("environment"),
+			ProjectID:   c.QueryParam("projectId"),
+			SecretPath:  c.QueryParam("secretPath"),
+		}
+	}
+
+	// Convert to JSON-LD CreateAction with import options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "CreateAction",
+		"import":   importOptions{Format: req.Format, Content: req.Content, Mode: req.Mode, DryRun: req.DryRun},
+	}
+	addRESTTarget(action, req.ProjectID, req.Environment, req.SecretPath)
+
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestImportSecrets_CreateUpsertAndDryRun(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "EXISTING", "old")
+	backend.set(scope, "SAME", "same")
+
+	entries := []secretEntry{
+		{Key: "NEW_KEY", Value: "new"},
+		{Key: "EXISTING", Value: "changed"},
+		{Key: "SAME", Value: "same"},
+		{Key: "1BAD", Value: "x"},
+		{Key: "NEW_KEY", Value: "again"},
+	}
+
+	preview, err := importSecrets(backend, scope, entries, importModeUpsert, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	wantStatus := []string{importCreated, importUpdated, importUnchanged, importInvalid, importInvalid}
+	for i, e := range preview {
+		if e.Status != wantStatus[i] {
+			t.Errorf("Dry run entry %d (%s): expected %s, got %s", i, e.Name, wantStatus[i], e.Status)
+		}
+	}
+	if _, err := backend.RetrieveSecret(scope, "NEW_KEY", 0); err == nil {
+		t.Fatal("Dry run must not write secrets")
+	}
+
+	report, err := importSecrets(backend, scope, entries, importModeCreate, false)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if report[0].Status != importCreated || report[0].Version != 1 || report[1].Status != importSkipped {
+		t.Errorf("Unexpected create-mode report: %+v", report)
+	}
+	if current, _ := backend.RetrieveSecret(scope, "EXISTING", 0); current.SecretValue != "old" {
+		t.Error("Create mode must not overwrite existing keys")
+	}
+
+	report, _ = importSecrets(backend, scope, entries[:2], importModeUpsert, false)
+	if report[0].Status != importUnchanged || report[1].Status != importUpdated || report[1].Version != 2 {
+		t.Errorf("Unexpected upsert report: %+v", report)
+	}
+}
+
+func TestImportOptions_Validation(t *testing.T) {
+	if err := (&importOptions{Content: "A=1", Mode: "replace"}).validate(); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+	if err := (&importOptions{}).validate(); err == nil {
+		t.Error("Expected error for empty content")
+	}
+	opts := importOptions{Content: "A=1"}
+	if err := opts.validate(); err != nil || opts.Mode != importModeCreate {
+		t.Errorf("Expected default create mode, got %q, %v", opts.Mode, err)
+	}
+}
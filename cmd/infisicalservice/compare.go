@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// handleCompare reports the differences between two scopes without writing
+// anything or revealing values
+func handleCompare(c echo.Context, action *semantic.SemanticAction, opts *compareOptions) error {
+	if err := opts.validate(); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid compare options", err)
+	}
+	var fp *fingerprinter
+	if opts.Fingerprints {
+		var err error
+		if fp, err = newFingerprinter(opts.Salt); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid compare options", err)
+		}
+	}
+
+	// Both sides resolve aliases and target defaults like any other target
+	var left renderScope
+	if len(opts.Left) == 0 || string(opts.Left) == "null" {
+		scope, identity, err := actionTarget(c, action)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to extract Infisical target", err)
+		}
+		left = renderScope{scope: scope, identity: identity}
+	} else {
+		var err error
+		if left, err = resolveRenderScope(opts.Left); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid compare options", fmt.Errorf("compare.left: %w", err))
+		}
+	}
+	right, err := resolveRenderScope(opts.Right)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid compare options", fmt.Errorf("compare.right: %w", err))
+	}
+	// One backend reads both sides, so both must use the same identity
+	if left.identity != right.identity {
+		return semantic.ReturnActionError(c, action, "Invalid compare options",
+			fmt.Errorf("cannot compare scopes of different identities (left %s, right %s)", identityName(left.identity), identityName(right.identity)))
+	}
+
+	if limited, err := rateLimits.CheckProject(c, left.scope.ProjectID); limited {
+		return err
+	}
+	if right.scope.ProjectID != left.scope.ProjectID {
+		if limited, err := rateLimits.CheckProject(c, right.scope.ProjectID); limited {
+			return err
+		}
+	}
+	conn, err := instanceConnection(serviceConfig.Replication.Instances, left.identity)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Infisical credentials not configured", err)
+	}
+	backend, err := connectBackend(conn)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to connect to Infisical", err)
+	}
+
+	report, err := diffScopes(backend, left.scope, right.scope, opts.keyFilter, fp)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to compare scopes", err)
+	}
+	log.Printf("Compared %s with %s: %d only left, %d only right, %d differing, %d identical",
+		left.scope, right.scope, len(report.OnlyInLeft), len(report.OnlyInRight), len(report.Differing), report.Identical)
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value:  report,
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// CompareRequest is the body of the REST compare endpoint
+type CompareRequest struct {
+	// Left and Right are each an alias name or a target object
+	Left         json.RawMessage `json:"left,omitempty"`
+	Right        json.RawMessage `json:"right"`
+	Keys         []string        `json:"keys,omitempty"`
+	Include      []string        `json:"include,omitempty"`
+	Exclude      []string        `json:"exclude,omitempty"`
+	Fingerprints bool            `json:"fingerprints,omitempty"`
+	Salt         string          `json:"salt,omitempty"`
+}
+
+// compareREST handles REST POST /v1/api/secrets/compare
+func compareREST(c echo.Context) error {
+	var req CompareRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD RetrieveAction with compare options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"compare": compareOptions{
+			Left:         req.Left,
+			Right:        req.Right,
+			keyFilter:    keyFilter{Keys: req.Keys, Include: req.Include, Exclude: req.Exclude},
+			Fingerprints: req.Fingerprints,
+			Salt:         req.Salt,
+		},
+	}
+	return callSemanticHandler(c, action)
+}
+
+// identityName describes the identity of a scope for error messages
+func identityName(identity string) string {
+	if identity == "" {
+		return "the default identity"
+	}
+	return fmt.Sprintf("identity %q", identity)
+}
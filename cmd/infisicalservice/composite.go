@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// originTemplate marks retrieved entries rendered from a composite template
+const originTemplate = "template"
+
+const (
+	// maxCompositeTemplates bounds the number of templates per action
+	maxCompositeTemplates = 32
+	// maxCompositeOutput bounds the rendered size of one template in bytes
+	maxCompositeOutput = 64 * 1024
+)
+
+// compositeFuncs is the function set available to composite templates in
+// addition to the text/template builtins
+var compositeFuncs = template.FuncMap{
+	"urlencode": url.QueryEscape,
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"join": func(sep string, items ...string) string {
+		return strings.Join(items, sep)
+	},
+	"default": func(def, value string) string {
+		if value == "" {
+			return def
+		}
+		return value
+	},
+}
+
+// compositeOptions is the RetrieveAction property deriving values such as DSNs
+// from the retrieved secrets. Templates see the secrets as a map by key.
+type compositeOptions struct {
+	// Templates maps the name of each derived value to its text/template source
+	Templates map[string]string `json:"templates"`
+	// Only returns just the rendered values instead of adding them to the secrets
+	Only bool `json:"only,omitempty"`
+}
+
+// parse checks the names and parses every template
+func (o *compositeOptions) parse() (map[string]*template.Template, error) {
+	if len(o.Templates) == 0 {
+		return nil, fmt.Errorf("composite.templates is required")
+	}
+	if len(o.Templates) > maxCompositeTemplates {
+		return nil, fmt.Errorf("at most %d composite templates are allowed", maxCompositeTemplates)
+	}
+	parsed := make(map[string]*template.Template, len(o.Templates))
+	for name, text := range o.Templates {
+		if !importKeyPattern.MatchString(name) {
+			return nil, fmt.Errorf("template name %q must start with a letter or underscore and contain only letters, digits, '_', '-' or '.'", name)
+		}
+		tmpl, err := template.New(name).Funcs(compositeFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		parsed[name] = tmpl
+	}
+	return parsed, nil
+}
+
+// limitedBuffer is a strings.Builder that fails once more than limit bytes are written
+type limitedBuffer struct {
+	strings.Builder
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("rendered value exceeds %d bytes", b.limit)
+	}
+	return b.Builder.Write(p)
+}
+
+// renderComposites evaluates the templates over the retrieved secrets and
+// returns the rendered entries sorted by name
+func renderComposites(templates map[string]*template.Template, secrets []interface{}) ([]interface{}, error) {
+	data := make(map[string]string, len(secrets))
+	for _, s := range secrets {
+		entry := s.(map[string]string)
+		data[entry["name"]] = entry["value"]
+	}
+
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rendered := make([]interface{}, len(names))
+	for i, name := range names {
+		out := &limitedBuffer{limit: maxCompositeOutput}
+		if err := templates[name].Execute(out, data); err != nil {
+			return nil, err
+		}
+		rendered[i] = map[string]string{"name": name, "value": out.String(), "origin": originTemplate}
+	}
+	return rendered, nil
+}
+
+// withComposites adds rendered entries to the retrieved secrets, or returns
+// them alone when only is set. Rendered names must not shadow secrets.
+func withComposites(secrets, rendered []interface{}, only bool) ([]interface{}, error) {
+	if only {
+		return rendered, nil
+	}
+	names := make(map[string]bool, len(secrets))
+	for _, s := range secrets {
+		names[s.(map[string]string)["name"]] = true
+	}
+	for _, r := range rendered {
+		if name := r.(map[string]string)["name"]; names[name] {
+			return nil, fmt.Errorf("template name %s collides with a retrieved secret; set composite.only or rename it", name)
+		}
+	}
+	return append(secrets, rendered...), nil
+}
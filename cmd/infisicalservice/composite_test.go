@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func compositeSecrets() []interface{} {
+	return []interface{}{
+		map[string]string{"name": "BASEX_URL", "value": "https://basex.internal:8984"},
+		map[string]string{"name": "BASEX_USERNAME", "value": "admin"},
+		map[string]string{"name": "BASEX_PASSWORD", "value": "p@ss/word"},
+		map[string]string{"name": "EMPTY", "value": ""},
+	}
+}
+
+func TestComposite_Render(t *testing.T) {
+	opts := compositeOptions{Templates: map[string]string{
+		"BASEX_DSN":  `{{ .BASEX_URL }}?user={{ urlencode .BASEX_USERNAME }}&password={{ urlencode .BASEX_PASSWORD }}`,
+		"BASIC_AUTH": `Basic {{ base64 (join ":" .BASEX_USERNAME .BASEX_PASSWORD) }}`,
+		"TIMEOUT":    `{{ .EMPTY | default "30s" }}/{{ index . "MISSING" | default "none" }}`,
+	}}
+	templates, err := opts.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := renderComposites(templates, compositeSecrets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"BASEX_DSN":  "https://basex.internal:8984?user=admin&password=p%40ss%2Fword",
+		"BASIC_AUTH": "Basic YWRtaW46cEBzcy93b3Jk",
+		"TIMEOUT":    "30s/none",
+	}
+	for _, r := range rendered {
+		entry := r.(map[string]string)
+		if entry["value"] != want[entry["name"]] || entry["origin"] != originTemplate {
+			t.Errorf("%s: expected %q, got %+v", entry["name"], want[entry["name"]], entry)
+		}
+	}
+
+	all, err := withComposites(compositeSecrets(), rendered, false)
+	if err != nil || len(all) != 7 {
+		t.Errorf("Expected secrets and rendered values, got %d entries, %v", len(all), err)
+	}
+	only, _ := withComposites(compositeSecrets(), rendered, true)
+	if len(only) != 3 {
+		t.Errorf("Expected only the rendered values, got %d entries", len(only))
+	}
+}
+
+func TestComposite_Errors(t *testing.T) {
+	missing := compositeOptions{Templates: map[string]string{"DSN": `{{ .NOT_THERE }}`}}
+	templates, err := missing.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renderComposites(templates, compositeSecrets()); err == nil || !strings.Contains(err.Error(), "NOT_THERE") {
+		t.Errorf("Expected a missing secret to be reported, got %v", err)
+	}
+
+	for name, opts := range map[string]compositeOptions{
+		"no templates": {},
+		"syntax":       {Templates: map[string]string{"DSN": `{{ .A `}},
+		"bad name":     {Templates: map[string]string{"1DSN": `x`}},
+		"unknown func": {Templates: map[string]string{"DSN": `{{ env "HOME" }}`}},
+	} {
+		if _, err := opts.parse(); err == nil {
+			t.Errorf("%s: expected the templates to be rejected", name)
+		}
+	}
+
+	shadow := []interface{}{map[string]string{"name": "BASEX_URL", "value": "x", "origin": originTemplate}}
+	if _, err := withComposites(compositeSecrets(), shadow, false); err == nil {
+		t.Error("Expected a template shadowing a secret to be rejected")
+	}
+
+	huge := compositeOptions{Templates: map[string]string{"BIG": `{{ range 3000 }}{{ $.BASEX_URL }}{{ end }}`}}
+	templates, err = huge.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renderComposites(templates, compositeSecrets()); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Expected oversized output to be rejected, got %v", err)
+	}
+}
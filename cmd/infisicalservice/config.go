@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ServiceConfig is the optional JSON configuration file referenced by
+// INFISICAL_SERVICE_CONFIG. Settings that are simple lists or switches are
+// read from environment variables instead.
+type ServiceConfig struct {
+	Access      AccessConfig      `json:"access"`
+	Rotation    RotationConfig    `json:"rotation"`
+	Expiry      ExpiryConfig      `json:"expiry"`
+	Replication ReplicationConfig `json:"replication"`
+	Aliases     AliasConfig       `json:"aliases,omitempty"`
+	Defaults    TargetDefaults    `json:"defaults"`
+	Validation  ValidationConfig  `json:"validation"`
+}
+
+// serviceConfig is the loaded service configuration (empty if no file is configured)
+var serviceConfig = &ServiceConfig{}
+
+// loadServiceConfig reads the configuration file at path. An empty path yields an empty configuration.
+func loadServiceConfig(path string) (*ServiceConfig, error) {
+	cfg := &ServiceConfig{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service config: %w", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse service config %s: %w", path, err)
+	}
+	if err := cfg.Access.validate(); err != nil {
+		return nil, fmt.Errorf("invalid service config %s: %w", path, err)
+	}
+	if err := cfg.Rotation.validate(); err != nil {
+		return nil, fmt.Errorf("invalid service config %s: %w", path, err)
+	}
+	if err := cfg.Expiry.validate(); err != nil {
+		return nil, fmt.Errorf("invalid service config %s: %w", path, err)
+	}
+	if err := cfg.Replication.validate(); err != nil {
+		return nil, fmt.Errorf("invalid service config %s: %w", path, err)
+	}
+	if err := cfg.Aliases.validate(cfg.Replication.Instances); err != nil {
+		return nil, fmt.Errorf("invalid service config %s: %w", path, err)
+	}
+	if err := cfg.Defaults.validate(); err != nil {
+		return nil, fmt.Errorf("invalid service config %s: %w", path, err)
+	}
+	if err := cfg.Validation.validate(); err != nil {
+		return nil, fmt.Errorf("invalid service config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// envList reads a comma-separated environment variable, falling back to def when unset
+func envList(name string, def []string) []string {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def
+	}
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+)
+
+// Environment variables supplying target defaults; they take precedence over
+// the defaults section of the configuration file
+const (
+	envDefaultProjectID      = "INFISICAL_PROJECT_ID"
+	envDefaultEnvironment    = "INFISICAL_ENV_SLUG"
+	envDefaultSecretPath     = "INFISICAL_SECRET_PATH"
+	envDefaultIncludeImports = "INFISICAL_INCLUDE_IMPORTS"
+)
+
+// TargetDefaults supplies the parts of a target that an action or REST query
+// omits. Precedence: action target, then INFISICAL_* environment variables,
+// then this configuration, then the built-in defaults (path "/", no imports).
+type TargetDefaults struct {
+	ProjectID      string `json:"projectId,omitempty"`
+	Environment    string `json:"environment,omitempty"`
+	SecretPath     string `json:"secretPath,omitempty"`
+	IncludeImports *bool  `json:"includeImports,omitempty"`
+}
+
+// validate normalizes the configured defaults
+func (d *TargetDefaults) validate() error {
+	if d.SecretPath != "" {
+		d.SecretPath = path.Clean("/" + d.SecretPath)
+	}
+	return nil
+}
+
+// effective returns the defaults with the environment variables applied
+func (d TargetDefaults) effective() (TargetDefaults, error) {
+	if v := os.Getenv(envDefaultProjectID); v != "" {
+		d.ProjectID = v
+	}
+	if v := os.Getenv(envDefaultEnvironment); v != "" {
+		d.Environment = v
+	}
+	if v := os.Getenv(envDefaultSecretPath); v != "" {
+		d.SecretPath = path.Clean("/" + v)
+	}
+	if v := os.Getenv(envDefaultIncludeImports); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return d, fmt.Errorf("%s %q is not a boolean", envDefaultIncludeImports, v)
+		}
+		d.IncludeImports = &b
+	}
+	return d, nil
+}
+
+// apply fills the omitted fields of scope. includeImportsSet tells whether the
+// target stated includeImports, since false cannot be told apart from omitted.
+func (d TargetDefaults) apply(scope secretScope, includeImportsSet bool) (secretScope, error) {
+	d, err := d.effective()
+	if err != nil {
+		return scope, err
+	}
+	if scope.ProjectID == "" {
+		if d.ProjectID == "" {
+			return scope, fmt.Errorf("projectId is required: set it in the target, %s or defaults.projectId", envDefaultProjectID)
+		}
+		scope.ProjectID = d.ProjectID
+	}
+	if scope.Environment == "" {
+		if d.Environment == "" {
+			return scope, fmt.Errorf("environment is required: set it in the target, %s or defaults.environment", envDefaultEnvironment)
+		}
+		scope.Environment = d.Environment
+	}
+	if scope.SecretPath == "" {
+		scope.SecretPath = d.SecretPath
+	}
+	if scope.SecretPath == "" {
+		scope.SecretPath = "/"
+	}
+	if !includeImportsSet && d.IncludeImports != nil {
+		scope.IncludeImports = *d.IncludeImports
+	}
+	return scope, nil
+}
+
+// targetSetsIncludeImports reports whether a raw target object states includeImports
+func targetSetsIncludeImports(raw json.RawMessage) bool {
+	var target map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &target); err != nil {
+		return false
+	}
+	_, ok := target["includeImports"]
+	return ok
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTargetDefaults_Precedence(t *testing.T) {
+	on := true
+	defaults := TargetDefaults{ProjectID: "file-project", Environment: "dev", SecretPath: "/app", IncludeImports: &on}
+	t.Setenv(envDefaultProjectID, "env-project")
+	t.Setenv(envDefaultEnvironment, "")
+	t.Setenv(envDefaultSecretPath, "")
+	t.Setenv(envDefaultIncludeImports, "")
+
+	scope, err := defaults.apply(secretScope{}, false)
+	want := secretScope{ProjectID: "env-project", Environment: "dev", SecretPath: "/app", IncludeImports: true}
+	if err != nil || scope != want {
+		t.Errorf("Expected environment over file defaults %+v, got %+v, %v", want, scope, err)
+	}
+
+	explicit := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/s3"}
+	if scope, _ := defaults.apply(explicit, true); scope != explicit {
+		t.Errorf("Expected the target to win over defaults, got %+v", scope)
+	}
+	if scope, _ := defaults.apply(explicit, false); !scope.IncludeImports {
+		t.Error("Expected an omitted includeImports to take the default")
+	}
+
+	t.Setenv(envDefaultIncludeImports, "maybe")
+	if _, err := defaults.apply(secretScope{}, false); err == nil {
+		t.Error("Expected an invalid INFISICAL_INCLUDE_IMPORTS to be rejected")
+	}
+}
+
+func TestTargetDefaults_MissingFieldNamed(t *testing.T) {
+	t.Setenv(envDefaultProjectID, "")
+	t.Setenv(envDefaultEnvironment, "")
+	if _, err := (TargetDefaults{}).apply(secretScope{}, false); err == nil || !strings.Contains(err.Error(), "projectId is required") {
+		t.Errorf("Expected the missing projectId to be named, got %v", err)
+	}
+	_, err := (TargetDefaults{ProjectID: "p"}).apply(secretScope{}, false)
+	if err == nil || !strings.Contains(err.Error(), "environment is required") || !strings.Contains(err.Error(), envDefaultEnvironment) {
+		t.Errorf("Expected the missing environment to be named, got %v", err)
+	}
+	if scope, _ := (TargetDefaults{ProjectID: "p", Environment: "prod"}).apply(secretScope{}, false); scope.SecretPath != "/" {
+		t.Errorf("Expected the built-in path /, got %q", scope.SecretPath)
+	}
+}
+
+func TestActionTarget_WithoutTarget(t *testing.T) {
+	saved := serviceConfig
+	defer func() { serviceConfig = saved }()
+	serviceConfig = &ServiceConfig{}
+	t.Setenv(envDefaultProjectID, "env-project")
+	t.Setenv(envDefaultEnvironment, "prod")
+
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+	c.Set(rawActionContextKey, []byte(`{"@type":"RetrieveAction"}`))
+	scope, _, err := actionTarget(c, nil)
+	if err != nil || scope != (secretScope{ProjectID: "env-project", Environment: "prod", SecretPath: "/"}) {
+		t.Errorf("Expected the defaults to supply the whole target, got %+v, %v", scope, err)
+	}
+}
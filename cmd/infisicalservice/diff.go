@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/infisical/go-sdk/packages/models"
+)
+
+// keyComparison is the state of one key across two scopes
+type keyComparison struct {
+	Name    string
+	InLeft  bool
+	InRight bool
+	// Equal is true when the key exists on both sides with the same value
+	Equal bool
+
+	leftValue  string
+	rightValue string
+}
+
+// compareSecretSets compares two lists of secrets by key, sorted by key
+func compareSecretSets(left, right []models.Secret) []keyComparison {
+	byName := make(map[string]*keyComparison)
+	for _, s := range left {
+		byName[s.SecretKey] = &keyComparison{Name: s.SecretKey, InLeft: true, leftValue: s.SecretValue}
+	}
+	for _, s := range right {
+		k, ok := byName[s.SecretKey]
+		if !ok {
+			k = &keyComparison{Name: s.SecretKey}
+			byName[s.SecretKey] = k
+		}
+		k.InRight, k.rightValue = true, s.SecretValue
+	}
+
+	comparisons := make([]keyComparison, 0, len(byName))
+	for _, k := range byName {
+		k.Equal = k.InLeft && k.InRight && hmac.Equal([]byte(k.leftValue), []byte(k.rightValue))
+		comparisons = append(comparisons, *k)
+	}
+	sort.Slice(comparisons, func(i, j int) bool { return comparisons[i].Name < comparisons[j].Name })
+	return comparisons
+}
+
+// keyFilter selects keys by explicit name and include/exclude glob patterns
+type keyFilter struct {
+	// Keys limits the selection to these names (empty: all keys)
+	Keys []string `json:"keys,omitempty"`
+	// Include limits the selection to keys matching any of these patterns (path.Match syntax)
+	Include []string `json:"include,omitempty"`
+	// Exclude removes keys matching any of these patterns
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// validate checks that every pattern is well-formed
+func (f keyFilter) validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid key pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// matches reports whether key is selected by the filter
+func (f keyFilter) matches(key string) bool {
+	if len(f.Keys) > 0 && !containsString(f.Keys, key) {
+		return false
+	}
+	if len(f.Include) > 0 && !matchesAny(f.Include, key) {
+		return false
+	}
+	return !matchesAny(f.Exclude, key)
+}
+
+func matchesAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// minFingerprintSaltLength is the minimum length of a caller-supplied fingerprint salt
+const minFingerprintSaltLength = 16
+
+// fingerprinter derives salted value fingerprints. Fingerprints are only
+// comparable when computed with the same salt, so a random salt confines them
+// to one response and prevents offline guessing across responses.
+type fingerprinter struct {
+	salt []byte
+}
+
+// newFingerprinter uses the given salt, or a random one when salt is empty
+func newFingerprinter(salt string) (*fingerprinter, error) {
+	if salt == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		return &fingerprinter{salt: b}, nil
+	}
+	if len(salt) < minFingerprintSaltLength {
+		return nil, fmt.Errorf("fingerprint salt must be at least %d characters", minFingerprintSaltLength)
+	}
+	return &fingerprinter{salt: []byte(salt)}, nil
+}
+
+// Fingerprint returns a truncated HMAC-SHA256 of value
+func (f *fingerprinter) Fingerprint(value string) string {
+	mac := hmac.New(sha256.New, f.salt)
+	mac.Write([]byte(value))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// compareOptions is the RetrieveAction property requesting a diff report of two scopes
+type compareOptions struct {
+	// Left and Right are each an alias name or a target object, filled from the
+	// target defaults as for render scopes. Left defaults to the action target.
+	Left  json.RawMessage `json:"left,omitempty"`
+	Right json.RawMessage `json:"right"`
+	keyFilter
+	// Fingerprints adds salted value hashes of both sides to the report
+	Fingerprints bool `json:"fingerprints,omitempty"`
+	// Salt makes fingerprints comparable across reports (random per report when empty)
+	Salt string `json:"salt,omitempty"`
+}
+
+// validate checks the options
+func (o *compareOptions) validate() error {
+	if len(o.Right) == 0 || string(o.Right) == "null" {
+		return fmt.Errorf("compare.right is required")
+	}
+	if o.Salt != "" && !o.Fingerprints {
+		return fmt.Errorf("compare.salt requires compare.fingerprints")
+	}
+	return o.keyFilter.validate()
+}
+
+// diffEntry is one key of a diff report. It never contains values.
+type diffEntry struct {
+	Name             string `json:"name"`
+	LeftFingerprint  string `json:"leftFingerprint,omitempty"`
+	RightFingerprint string `json:"rightFingerprint,omitempty"`
+}
+
+// diffReport lists the keys that are only in one scope or differ between them
+type diffReport struct {
+	Left        secretScope `json:"left"`
+	Right       secretScope `json:"right"`
+	OnlyInLeft  []diffEntry `json:"onlyInLeft"`
+	OnlyInRight []diffEntry `json:"onlyInRight"`
+	Differing   []diffEntry `json:"differing"`
+	Identical   int         `json:"identical"`
+}
+
+// diffScopes compares the selected keys of two scopes
+func diffScopes(backend secretBackend, left, right secretScope, filter keyFilter, fp *fingerprinter) (*diffReport, error) {
+	leftSecrets, err := backend.ListSecrets(left)
+	if err != nil {
+		return nil, fmt.Errorf("left: %w", err)
+	}
+	rightSecrets, err := backend.ListSecrets(right)
+	if err != nil {
+		return nil, fmt.Errorf("right: %w", err)
+	}
+
+	report := &diffReport{Left: left, Right: right, OnlyInLeft: []diffEntry{}, OnlyInRight: []diffEntry{}, Differing: []diffEntry{}}
+	for _, k := range compareSecretSets(leftSecrets, rightSecrets) {
+		if !filter.matches(k.Name) {
+			continue
+		}
+		if k.Equal {
+			report.Identical++
+			continue
+		}
+		entry := diffEntry{Name: k.Name}
+		if fp != nil {
+			if k.InLeft {
+				entry.LeftFingerprint = fp.Fingerprint(k.leftValue)
+			}
+			if k.InRight {
+				entry.RightFingerprint = fp.Fingerprint(k.rightValue)
+			}
+		}
+		switch {
+		case !k.InRight:
+			report.OnlyInLeft = append(report.OnlyInLeft, entry)
+		case !k.InLeft:
+			report.OnlyInRight = append(report.OnlyInRight, entry)
+		default:
+			report.Differing = append(report.Differing, entry)
+		}
+	}
+	return report, nil
+}
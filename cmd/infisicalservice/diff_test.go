@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFingerprinter(t *testing.T) {
+	a, _ := newFingerprinter("")
+	b, _ := newFingerprinter("")
+	if a.Fingerprint("secret") != a.Fingerprint("secret") {
+		t.Error("Fingerprints must be stable for one salt")
+	}
+	if a.Fingerprint("secret") == b.Fingerprint("secret") {
+		t.Error("Random salts must yield different fingerprints")
+	}
+	if _, err := newFingerprinter("short"); err == nil {
+		t.Error("Expected error for a short salt")
+	}
+}
+
+func TestKeyFilter(t *testing.T) {
+	f := keyFilter{Include: []string{"DB_*", "S3_*"}, Exclude: []string{"*_PASSWORD"}}
+	for key, want := range map[string]bool{"DB_HOST": true, "DB_PASSWORD": false, "S3_BUCKET": true, "API_KEY": false} {
+		if got := f.matches(key); got != want {
+			t.Errorf("%s: expected %v, got %v", key, want, got)
+		}
+	}
+}
+
+func TestDiffScopes(t *testing.T) {
+	staging := secretScope{ProjectID: "p1", Environment: "staging", SecretPath: "/"}
+	prod := secretScope{ProjectID: "p2", Environment: "prod", SecretPath: "/app"}
+	backend := newFakeBackend()
+	backend.set(staging, "API_URL", "https://staging")
+	backend.set(staging, "LOG_LEVEL", "info")
+	backend.set(staging, "NEW_FLAG", "on")
+	backend.set(prod, "API_URL", "https://prod")
+	backend.set(prod, "LOG_LEVEL", "info")
+	backend.set(prod, "LEGACY", "old")
+
+	fp, _ := newFingerprinter("0123456789abcdef")
+	report, err := diffScopes(backend, staging, prod, keyFilter{}, fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.OnlyInLeft) != 1 || report.OnlyInLeft[0].Name != "NEW_FLAG" || report.OnlyInLeft[0].RightFingerprint != "" {
+		t.Errorf("Unexpected onlyInLeft: %+v", report.OnlyInLeft)
+	}
+	if len(report.OnlyInRight) != 1 || report.OnlyInRight[0].Name != "LEGACY" {
+		t.Errorf("Unexpected onlyInRight: %+v", report.OnlyInRight)
+	}
+	if len(report.Differing) != 1 || report.Differing[0].Name != "API_URL" || report.Identical != 1 {
+		t.Errorf("Unexpected differing/identical: %+v / %d", report.Differing, report.Identical)
+	}
+	if report.Differing[0].LeftFingerprint != fp.Fingerprint("https://staging") {
+		t.Error("Expected fingerprints to be reproducible with the caller's salt")
+	}
+	out, _ := json.Marshal(report)
+	if strings.Contains(string(out), "https://") {
+		t.Fatal("Diff report must not contain values")
+	}
+
+	report, _ = diffScopes(backend, staging, prod, keyFilter{Exclude: []string{"API_*"}}, nil)
+	if len(report.Differing) != 0 || report.OnlyInLeft[0].LeftFingerprint != "" {
+		t.Errorf("Expected filtered report without fingerprints, got %+v", report)
+	}
+}
+
+func TestCompareOptions_Validation(t *testing.T) {
+	opts := &compareOptions{Right: json.RawMessage(`"iqs-s3/prod"`)}
+	if err := opts.validate(); err != nil {
+		t.Errorf("Expected valid options, got %v", err)
+	}
+	if err := (&compareOptions{}).validate(); err == nil {
+		t.Error("Expected error without right scope")
+	}
+	opts.Salt = "0123456789abcdef"
+	if err := opts.validate(); err == nil {
+		t.Error("Expected error for salt without fingerprints")
+	}
+}
+
+func TestCompare_AliasesAndDefaults(t *testing.T) {
+	saved := serviceConfig
+	defer func() { serviceConfig = saved }()
+	serviceConfig = &ServiceConfig{Aliases: AliasConfig{
+		"iqs-s3":    {ProjectID: "p-s3", SecretPath: "/"},
+		"eu-backup": {Identity: "eu", ProjectID: "p-s3", Environment: "prod", SecretPath: "/"},
+	}}
+	t.Setenv(envDefaultProjectID, "p-s3")
+	t.Setenv(envDefaultEnvironment, "")
+
+	staging := secretScope{ProjectID: "p-s3", Environment: "staging", SecretPath: "/"}
+	prod := secretScope{ProjectID: "p-s3", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(staging, "S3_BUCKET", "staging-bucket")
+	backend.set(prod, "S3_BUCKET", "prod-bucket")
+	backend.set(prod, "S3_REGION", "fsn1")
+	useFakeBackend(t, backend)
+
+	compare := func(left, right string) *httptest.ResponseRecorder {
+		opts := map[string]interface{}{"right": json.RawMessage(right)}
+		if left != "" {
+			opts["left"] = json.RawMessage(left)
+		}
+		return serveAction(t, map[string]interface{}{"@type": "RetrieveAction", "compare": opts})
+	}
+
+	// An alias pair and a target object filled from INFISICAL_PROJECT_ID compare the same scopes
+	for _, left := range []string{`"iqs-s3/staging"`, `{"environment":"staging"}`} {
+		rec := compare(left, `"iqs-s3/prod"`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", left, rec.Code, rec.Body)
+		}
+		var response struct {
+			Result struct {
+				Value diffReport `json:"value"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		report := response.Result.Value
+		if report.Left != staging || report.Right != prod || len(report.Differing) != 1 || len(report.OnlyInRight) != 1 {
+			t.Errorf("%s: unexpected report %+v", left, report)
+		}
+	}
+
+	rec := compare(`"iqs-s3/staging"`, `"eu-backup"`)
+	if rec.Code == http.StatusOK || !strings.Contains(rec.Body.String(), "different identities") {
+		t.Errorf("Expected a cross-identity comparison to be rejected, got %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// discoverOptions is the RetrieveAction property requesting the projects and
+// environments the configured identity can access. It needs no target.
+type discoverOptions struct {
+	// ProjectID restricts discovery to a single project
+	ProjectID string `json:"projectId,omitempty"`
+	// Folders adds the folder tree of each environment; requires ProjectID
+	Folders bool `json:"folders,omitempty"`
+	// Recursive lists the whole folder tree instead of the top level
+	Recursive bool `json:"recursive,omitempty"`
+}
+
+// validate checks the options
+func (o *discoverOptions) validate() error {
+	if o.Folders && o.ProjectID == "" {
+		return fmt.Errorf("discover.projectId is required to list folders")
+	}
+	if o.Recursive && !o.Folders {
+		return fmt.Errorf("discover.recursive requires discover.folders")
+	}
+	return nil
+}
+
+// discoveredEnvironment is an environment with its folders when requested
+type discoveredEnvironment struct {
+	projectEnvironment
+	Folders []folderEntry `json:"folders,omitempty"`
+}
+
+// discoveredProject is one project of a discovery listing
+type discoveredProject struct {
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Slug         string                  `json:"slug,omitempty"`
+	Environments []discoveredEnvironment `json:"environments"`
+}
+
+// findProject returns the accessible project with the given ID
+func findProject(backend secretBackend, projectID string) (*project, error) {
+	projects, err := backend.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	for i := range projects {
+		if projects[i].ID == projectID {
+			return &projects[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// discover lists the accessible projects, or the selected one, with their environments and optionally folders
+func discover(backend secretBackend, opts *discoverOptions) ([]discoveredProject, error) {
+	projects, err := backend.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	var result []discoveredProject
+	for _, p := range projects {
+		if opts.ProjectID != "" && p.ID != opts.ProjectID {
+			continue
+		}
+		entry := discoveredProject{ID: p.ID, Name: p.Name, Slug: p.Slug, Environments: make([]discoveredEnvironment, len(p.Environments))}
+		for i, env := range p.Environments {
+			entry.Environments[i] = discoveredEnvironment{projectEnvironment: env}
+			if !opts.Folders {
+				continue
+			}
+			scope := secretScope{ProjectID: p.ID, Environment: env.Slug, SecretPath: "/"}
+			if entry.Environments[i].Folders, err = listFolders(backend, scope, opts.Recursive); err != nil {
+				return nil, fmt.Errorf("failed to list folders of %s: %w", scope, err)
+			}
+		}
+		result = append(result, entry)
+	}
+	if opts.ProjectID != "" && len(result) == 0 {
+		return nil, fmt.Errorf("project %s is not accessible", opts.ProjectID)
+	}
+	return result, nil
+}
+
+// validateTarget checks that the project of scope is accessible and that its
+// environment and secret path exist. It returns one problem per failed check.
+func validateTarget(backend secretBackend, scope secretScope) ([]string, error) {
+	p, err := findProject(backend, scope.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return []string{fmt.Sprintf("project %s is not accessible", scope.ProjectID)}, nil
+	}
+
+	var slugs []string
+	found := false
+	for _, env := range p.Environments {
+		slugs = append(slugs, env.Slug)
+		found = found || env.Slug == scope.Environment
+	}
+	if !found {
+		return []string{fmt.Sprintf("environment %q does not exist in project %s (available: %s)", scope.Environment, scope.ProjectID, strings.Join(slugs, ", "))}, nil
+	}
+
+	// Walk the secret path one folder at a time
+	parent := secretScope{ProjectID: scope.ProjectID, Environment: scope.Environment, SecretPath: "/"}
+	for _, name := range strings.Split(strings.Trim(path.Clean("/"+scope.SecretPath), "/"), "/") {
+		if name == "" {
+			break
+		}
+		folders, err := backend.ListFolders(parent)
+		if err != nil {
+			return nil, err
+		}
+		exists := false
+		for _, folder := range folders {
+			exists = exists || folder.Name == name
+		}
+		parent = childScope(parent, name)
+		if !exists {
+			return []string{fmt.Sprintf("folder %s does not exist in environment %q", parent.SecretPath, scope.Environment)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// handleDiscover lists the projects and environments the identity can access
+func handleDiscover(c echo.Context, action *semantic.SemanticAction, opts *discoverOptions) error {
+	if err := opts.validate(); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid discover options", err)
+	}
+	if opts.ProjectID != "" {
+		if limited, err := rateLimits.CheckProject(c, opts.ProjectID); limited {
+			return err
+		}
+	}
+	conn, err := infisicalConnectionFromEnv()
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Infisical credentials not configured", err)
+	}
+	backend, err := connectBackend(conn)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to connect to Infisical", err)
+	}
+
+	projects, err := discover(backend, opts)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to discover projects", err)
+	}
+	log.Printf("Discovered %d projects (folders=%v)", len(projects), opts.Folders)
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"projects": append([]discoveredProject{}, projects...),
+		},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// handleValidateTarget reports whether the target of the action exists
+func handleValidateTarget(c echo.Context, action *semantic.SemanticAction) error {
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	problems, err := validateTarget(backend, scope)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to validate target", err)
+	}
+	log.Printf("Validated target %s: %d problems", scope, len(problems))
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"projectId":   scope.ProjectID,
+			"environment": scope.Environment,
+			"secretPath":  scope.SecretPath,
+			"valid":       len(problems) == 0,
+			"problems":    append([]string{}, problems...),
+		},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// listProjectsREST handles REST GET /v1/api/projects
+func listProjectsREST(c echo.Context) error {
+	// Convert to JSON-LD RetrieveAction with discover options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"discover": discoverOptions{},
+	}
+
+	return callSemanticHandler(c, action)
+}
+
+// getProjectREST handles REST GET /v1/api/projects/:projectId
+func getProjectREST(c echo.Context) error {
+	folders, _ := strconv.ParseBool(c.QueryParam("folders"))
+	recursive, _ := strconv.ParseBool(c.QueryParam("recursive"))
+
+	// Convert to JSON-LD RetrieveAction with discover options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"discover": discoverOptions{ProjectID: c.Param("projectId"), Folders: folders, Recursive: recursive},
+	}
+
+	return callSemanticHandler(c, action)
+}
+
+// validateTargetREST handles REST GET /v1/api/targets/validate
+func validateTargetREST(c echo.Context) error {
+	// Convert to JSON-LD RetrieveAction with validateTarget
+	action := map[string]interface{}{
+		"@context":       "https://schema.org",
+		"@type":          "RetrieveAction",
+		"validateTarget": true,
+	}
+	addRESTTarget(action, c.QueryParam("projectId"), c.QueryParam("environment"), c.QueryParam("path"))
+
+	return callSemanticHandler(c, action)
+}
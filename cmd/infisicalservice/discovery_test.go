@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func discoveryBackend() *fakeBackend {
+	backend := newFakeBackend()
+	backend.projects = []project{
+		{ID: "p1", Name: "S3", Environments: []projectEnvironment{{Name: "Development", Slug: "dev"}, {Name: "Production", Slug: "prod"}}},
+		{ID: "p2", Name: "BaseX", Environments: []projectEnvironment{{Name: "Production", Slug: "prod"}}},
+	}
+	prod := secretScope{ProjectID: "p1", Environment: "prod", SecretPath: "/"}
+	backend.CreateFolder(prod, "app")
+	backend.CreateFolder(childScope(prod, "app"), "db")
+	return backend
+}
+
+func TestDiscover_ProjectsAndFolders(t *testing.T) {
+	backend := discoveryBackend()
+
+	all, err := discover(backend, &discoverOptions{})
+	if err != nil || len(all) != 2 || all[0].Environments[0].Folders != nil {
+		t.Fatalf("Expected both projects without folders, got %+v, %v", all, err)
+	}
+
+	one, err := discover(backend, &discoverOptions{ProjectID: "p1", Folders: true, Recursive: true})
+	if err != nil || len(one) != 1 {
+		t.Fatalf("Expected only p1, got %+v, %v", one, err)
+	}
+	prod := one[0].Environments[1]
+	if prod.Slug != "prod" || len(prod.Folders) != 2 || prod.Folders[1].Path != "/app/db" {
+		t.Errorf("Unexpected prod folder tree: %+v", prod)
+	}
+	if len(one[0].Environments[0].Folders) != 0 {
+		t.Errorf("Expected dev to have no folders, got %+v", one[0].Environments[0].Folders)
+	}
+
+	if _, err := discover(backend, &discoverOptions{ProjectID: "missing"}); err == nil {
+		t.Error("Expected an inaccessible project to be reported")
+	}
+	if err := (&discoverOptions{Folders: true}).validate(); err == nil {
+		t.Error("Expected folders without projectId to be rejected")
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	backend := discoveryBackend()
+	cases := []struct {
+		scope   secretScope
+		problem string
+	}{
+		{secretScope{ProjectID: "p1", Environment: "prod", SecretPath: "/"}, ""},
+		{secretScope{ProjectID: "p1", Environment: "prod", SecretPath: "/app/db"}, ""},
+		{secretScope{ProjectID: "p3", Environment: "prod", SecretPath: "/"}, "project p3"},
+		{secretScope{ProjectID: "p2", Environment: "dev", SecretPath: "/"}, "available: prod"},
+		{secretScope{ProjectID: "p1", Environment: "prod", SecretPath: "/app/cache"}, "folder /app/cache"},
+	}
+	for _, tc := range cases {
+		problems, err := validateTarget(backend, tc.scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.problem == "" && len(problems) != 0 {
+			t.Errorf("%s: expected no problems, got %v", tc.scope, problems)
+		}
+		if tc.problem != "" && (len(problems) != 1 || !strings.Contains(problems[0], tc.problem)) {
+			t.Errorf("%s: expected a problem mentioning %q, got %v", tc.scope, tc.problem, problems)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/infisical/go-sdk/packages/models"
+	"github.com/labstack/echo/v4"
+)
+
+// expiryMetadataKey is the Infisical secret metadata key holding the expiry date
+const expiryMetadataKey = "expiresAt"
+
+// defaultExpiryWarnWithin is how far ahead of expiry secrets are flagged by default
+const defaultExpiryWarnWithin = 7 * 24 * time.Hour
+
+// Expiry states reported for secrets near or past their expiry date
+const (
+	expiryExpiring = "expiring"
+	expiryExpired  = "expired"
+)
+
+// expiryCommentPattern matches the comment convention "expires: 2025-12-31" (or expiresAt=...)
+var expiryCommentPattern = regexp.MustCompile(`(?i)\bexpires(?:[ _-]?at)?\s*[:=]\s*(\d{4}-\d{2}-\d{2}(?:T[0-9:.]+(?:Z|[+-]\d{2}:\d{2}))?)`)
+
+// ExpiryConfig configures expiry warnings and the expiring-secrets report
+type ExpiryConfig struct {
+	// WarnWithin flags secrets expiring within this window (e.g. "14d" or "336h", default 7d)
+	WarnWithin string `json:"warnWithin,omitempty"`
+	// Scopes are the project folders scanned by the expiring-secrets report
+	Scopes []secretScope `json:"scopes,omitempty"`
+
+	warnWithin time.Duration
+}
+
+// validate checks the expiry configuration and applies defaults
+func (e *ExpiryConfig) validate() error {
+	if e.WarnWithin != "" {
+		d, err := parseWindow(e.WarnWithin)
+		if err != nil {
+			return fmt.Errorf("expiry.warnWithin: %w", err)
+		}
+		e.warnWithin = d
+	}
+	for i := range e.Scopes {
+		if e.Scopes[i].ProjectID == "" || e.Scopes[i].Environment == "" {
+			return fmt.Errorf("expiry.scopes[%d]: projectId and environment are required", i)
+		}
+		if e.Scopes[i].SecretPath == "" {
+			e.Scopes[i].SecretPath = "/"
+		}
+	}
+	return nil
+}
+
+// window returns the warning window, falling back to the default
+func (e *ExpiryConfig) window() time.Duration {
+	if e.warnWithin > 0 {
+		return e.warnWithin
+	}
+	return defaultExpiryWarnWithin
+}
+
+// parseWindow parses a positive Go duration or a number of days such as "30d"
+func parseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%q is not a positive number of days", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%q is not a positive duration", s)
+	}
+	return d, nil
+}
+
+// parseExpiry parses an RFC 3339 timestamp or a YYYY-MM-DD date (midnight UTC)
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expiry %q must be an RFC 3339 timestamp or YYYY-MM-DD date", s)
+	}
+	return t, nil
+}
+
+// parseExpiryOption parses the expiresAt action property. "none" yields the
+// zero time, which removes the expiry.
+func parseExpiryOption(s string) (time.Time, error) {
+	if s == "" || strings.EqualFold(s, "none") {
+		return time.Time{}, nil
+	}
+	return parseExpiry(s)
+}
+
+// formatExpiry formats an expiry for results; the zero time means no expiry
+func formatExpiry(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+// secretExpiry reads the expiry of a secret from its metadata, falling back
+// to the comment convention
+func secretExpiry(secret models.Secret) (time.Time, bool) {
+	for _, m := range secret.SecretMetadata {
+		if m.Key == expiryMetadataKey {
+			t, err := parseExpiry(m.Value)
+			return t, err == nil
+		}
+	}
+	if match := expiryCommentPattern.FindStringSubmatch(secret.SecretComment); match != nil {
+		t, err := parseExpiry(match[1])
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// expiryState returns expired or expiring when the expiry is past or within the window
+func expiryState(expiresAt, now time.Time, within time.Duration) string {
+	switch {
+	case !now.Before(expiresAt):
+		return expiryExpired
+	case expiresAt.Sub(now) <= within:
+		return expiryExpiring
+	}
+	return ""
+}
+
+// addExpiryWarning adds expiresAt and expiryWarning to a retrieved entry when
+// the secret is near or past its expiry
+func addExpiryWarning(entry map[string]string, secret models.Secret, now time.Time) {
+	expiresAt, ok := secretExpiry(secret)
+	if !ok {
+		return
+	}
+	if state := expiryState(expiresAt, now, serviceConfig.Expiry.window()); state != "" {
+		entry["expiresAt"] = expiresAt.Format(time.RFC3339)
+		entry["expiryWarning"] = state
+		log.Printf("WARNING: secret %s %s at %s", redaction.KeyName(secret.SecretKey), state, entry["expiresAt"])
+	}
+}
+
+// setSecretExpiry stores (or with the zero time removes) the expiry of a secret,
+// keeping its other metadata
+func setSecretExpiry(backend secretBackend, scope secretScope, key string, expiresAt time.Time) (models.Secret, error) {
+	secret, err := backend.RetrieveSecret(scope, key, 0)
+	if err != nil {
+		return models.Secret{}, err
+	}
+	var metadata []models.SecretMetadata
+	for _, m := range secret.SecretMetadata {
+		if m.Key != expiryMetadataKey {
+			metadata = append(metadata, m)
+		}
+	}
+	if !expiresAt.IsZero() {
+		metadata = append(metadata, models.SecretMetadata{Key: expiryMetadataKey, Value: expiresAt.Format(time.RFC3339)})
+	}
+	return backend.UpdateSecretMetadata(scope, key, metadata)
+}
+
+// expiringSecret is one entry of the expiring-secrets report. It never contains values.
+type expiringSecret struct {
+	ProjectID   string    `json:"projectId"`
+	Environment string    `json:"environment"`
+	SecretPath  string    `json:"secretPath"`
+	Name        string    `json:"name"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Status      string    `json:"status"`
+	DaysLeft    int       `json:"daysLeft"`
+}
+
+// scopeError reports a scope the expiring-secrets report could not read
+type scopeError struct {
+	Scope string `json:"scope"`
+	Error string `json:"error"`
+}
+
+// expiringSecrets lists secrets in the given scopes expiring within the window, soonest first
+func expiringSecrets(backend secretBackend, scopes []secretScope, within time.Duration, now time.Time) ([]expiringSecret, []scopeError) {
+	expiring := []expiringSecret{}
+	var failures []scopeError
+	for _, scope := range scopes {
+		secrets, err := backend.ListSecrets(scope)
+		if err != nil {
+			failures = append(failures, scopeError{Scope: scope.String(), Error: err.Error()})
+			continue
+		}
+		for _, secret := range secrets {
+			expiresAt, ok := secretExpiry(secret)
+			if !ok {
+				continue
+			}
+			state := expiryState(expiresAt, now, within)
+			if state == "" {
+				continue
+			}
+			expiring = append(expiring, expiringSecret{
+				ProjectID:   scope.ProjectID,
+				Environment: scope.Environment,
+				SecretPath:  scope.SecretPath,
+				Name:        secret.SecretKey,
+				ExpiresAt:   expiresAt,
+				Status:      state,
+				DaysLeft:    int(expiresAt.Sub(now).Hours() / 24),
+			})
+		}
+	}
+	sort.SliceStable(expiring, func(i, j int) bool { return expiring[i].ExpiresAt.Before(expiring[j].ExpiresAt) })
+	return expiring, failures
+}
+
+// expiringOptions is the RetrieveAction property requesting the expiring-secrets report
+type expiringOptions struct {
+	// Within is the look-ahead window (default expiry.warnWithin)
+	Within string `json:"within,omitempty"`
+}
+
+// handleExpiringReport lists secrets expiring soon across the configured expiry scopes
+func handleExpiringReport(c echo.Context, action *semantic.SemanticAction, opts *expiringOptions) error {
+	within := serviceConfig.Expiry.window()
+	if opts.Within != "" {
+		d, err := parseWindow(opts.Within)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid expiring options", err)
+		}
+		within = d
+	}
+	scopes := serviceConfig.Expiry.Scopes
+	if len(scopes) == 0 {
+		return semantic.ReturnActionError(c, action, "Invalid expiring options", fmt.Errorf("no expiry.scopes configured"))
+	}
+
+	checked := map[string]bool{}
+	for _, scope := range scopes {
+		if checked[scope.ProjectID] {
+			continue
+		}
+		checked[scope.ProjectID] = true
+		if limited, err := rateLimits.CheckProject(c, scope.ProjectID); limited {
+			return err
+		}
+	}
+
+	conn, err := infisicalConnectionFromEnv()
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Infisical credentials not configured", err)
+	}
+	backend, err := connectBackend(conn)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to connect to Infisical", err)
+	}
+
+	now := time.Now().UTC()
+	expiring, failures := expiringSecrets(backend, scopes, within, now)
+	log.Printf("Expiring-secrets report: %d secrets within %s across %d scopes", len(expiring), within, len(scopes))
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"generatedAt": now.Format(time.RFC3339),
+			"within":      within.String(),
+			"scopes":      len(scopes),
+			"secrets":     expiring,
+			"errors":      failures,
+		},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// getExpiringSecretsREST handles REST GET /v1/api/expiring-secrets
+func getExpiringSecretsREST(c echo.Context) error {
+	// Convert to JSON-LD RetrieveAction with expiring options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"expiring": expiringOptions{Within: c.QueryParam("within")},
+	}
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/infisical/go-sdk/packages/models"
+)
+
+func TestSecretExpiry_MetadataAndCommentConvention(t *testing.T) {
+	fromMetadata := models.Secret{SecretMetadata: []models.SecretMetadata{{Key: "owner", Value: "ops"}, {Key: "expiresAt", Value: "2025-06-30T12:00:00Z"}}}
+	if got, ok := secretExpiry(fromMetadata); !ok || !got.Equal(time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected expiry from metadata, got %v, %v", got, ok)
+	}
+
+	fromComment := models.Secret{SecretComment: "Stripe API token, Expires: 2025-07-01 (renew in dashboard)"}
+	if got, ok := secretExpiry(fromComment); !ok || !got.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected expiry from comment, got %v, %v", got, ok)
+	}
+
+	if _, ok := secretExpiry(models.Secret{SecretComment: "rotated quarterly"}); ok {
+		t.Error("Expected no expiry without metadata or convention")
+	}
+}
+
+func TestSetSecretExpiry_KeepsOtherMetadata(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "API_TOKEN", "token")
+	backend.metadata[fakeScopeKey(scope)+"#API_TOKEN"] = []models.SecretMetadata{{Key: "owner", Value: "ops"}}
+
+	expiresAt := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	secret, err := setSecretExpiry(backend, scope, "API_TOKEN", expiresAt)
+	if err != nil {
+		t.Fatalf("setSecretExpiry failed: %v", err)
+	}
+	if len(secret.SecretMetadata) != 2 {
+		t.Errorf("Expected owner and expiresAt metadata, got %v", secret.SecretMetadata)
+	}
+
+	secret, _ = setSecretExpiry(backend, scope, "API_TOKEN", time.Time{})
+	if _, ok := secretExpiry(secret); ok || len(secret.SecretMetadata) != 1 {
+		t.Errorf("Expected expiry removed and owner kept, got %v", secret.SecretMetadata)
+	}
+}
+
+func TestExpiringSecrets_Report(t *testing.T) {
+	prod := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for key, expiry := range map[string]string{
+		"EXPIRED_TOKEN": "2025-05-20",
+		"SOON_TOKEN":    "2025-06-05",
+		"LATER_TOKEN":   "2025-09-01",
+	} {
+		backend.set(prod, key, "value-"+key)
+		expiresAt, _ := parseExpiry(expiry)
+		if _, err := setSecretExpiry(backend, prod, key, expiresAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backend.set(prod, "NO_EXPIRY", "value")
+
+	report, failures := expiringSecrets(backend, []secretScope{prod}, 30*24*time.Hour, now)
+	if len(failures) != 0 {
+		t.Fatalf("Unexpected scope errors: %v", failures)
+	}
+	if len(report) != 2 || report[0].Name != "EXPIRED_TOKEN" || report[0].Status != expiryExpired ||
+		report[1].Name != "SOON_TOKEN" || report[1].Status != expiryExpiring || report[1].DaysLeft != 4 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	entry := map[string]string{"name": "SOON_TOKEN", "value": "x"}
+	secret, _ := backend.RetrieveSecret(prod, "SOON_TOKEN", 0)
+	addExpiryWarning(entry, secret, now)
+	if entry["expiryWarning"] != expiryExpiring || entry["expiresAt"] != "2025-06-05T00:00:00Z" {
+		t.Errorf("Expected expiry warning on retrieved entry, got %v", entry)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	if d, err := parseWindow("14d"); err != nil || d != 14*24*time.Hour {
+		t.Errorf("Unexpected window %v, %v", d, err)
+	}
+	for _, invalid := range []string{"0d", "-1h", "soon"} {
+		if _, err := parseWindow(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/infisical/go-sdk/packages/models"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeBackend is an in-memory secretBackend keeping the full version history of every secret
+type fakeBackend struct {
+	// secrets maps a scope key ("project/env/path") to secret key to versions (oldest first)
+	secrets map[string]map[string][]fakeVersion
+	// metadata maps a secret ID to its metadata
+	metadata map[string][]models.SecretMetadata
+	// folders maps a parent scope key to folder name to folder
+	folders map[string]map[string]models.Folder
+	// imports maps a scope key to its secret imports in precedence order
+	imports map[string][]secretImport
+	// projects is returned by ListProjects
+	projects []project
+	now      time.Time
+	// failKeys makes every write or delete of these keys fail
+	failKeys map[string]bool
+	// failReads makes RetrieveSecret fail as on an unreachable server
+	failReads bool
+}
+
+type fakeVersion struct {
+	value     string
+	createdAt time.Time
+	actor     string
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		secrets:  make(map[string]map[string][]fakeVersion),
+		metadata: make(map[string][]models.SecretMetadata),
+		folders:  make(map[string]map[string]models.Folder),
+		imports:  make(map[string][]secretImport),
+		failKeys: make(map[string]bool),
+		now:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// useFakeBackend makes connectBackend return b and configures dummy credentials
+func useFakeBackend(t *testing.T, b *fakeBackend) {
+	t.Helper()
+	t.Setenv("INFISICAL_CLIENT_ID", "test-client-id")
+	t.Setenv("INFISICAL_CLIENT_SECRET", "test-client-secret")
+	original := connectBackend
+	connectBackend = func(conn infisicalConnection) (secretBackend, error) {
+		return b, nil
+	}
+	t.Cleanup(func() { connectBackend = original })
+}
+
+var registerHandlersOnce sync.Once
+
+// registerHandlers registers the action handlers once, as main does, without operation tracking
+func registerHandlers() {
+	registerHandlersOnce.Do(func() {
+		semantic.MustRegister("CreateAction", handleCreateAction)
+		semantic.MustRegister("RetrieveAction", handleRetrieveAction)
+		semantic.MustRegister("UpdateAction", handleUpdateAction)
+		semantic.MustRegister("DeleteAction", handleDeleteAction)
+		semantic.MustRegister("TransferAction", handleTransferAction)
+	})
+}
+
+// serveAction sends a JSON-LD action through callSemanticHandler, the path every
+// REST adapter takes, and returns the recorded response
+func serveAction(t *testing.T, action map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	registerHandlers()
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/v1/api/semantic/action", nil), rec)
+	if err := callSemanticHandler(c, action); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func fakeScopeKey(scope secretScope) string {
+	return scope.ProjectID + "/" + scope.Environment + "/" + scope.SecretPath
+}
+
+// set writes a new version of a secret, one hour after the previous write
+func (b *fakeBackend) set(scope secretScope, key, value string) {
+	k := fakeScopeKey(scope)
+	if b.secrets[k] == nil {
+		b.secrets[k] = make(map[string][]fakeVersion)
+	}
+	b.now = b.now.Add(time.Hour)
+	b.secrets[k][key] = append(b.secrets[k][key], fakeVersion{value: value, createdAt: b.now, actor: "identity test"})
+}
+
+func (b *fakeBackend) secret(scope secretScope, key string, versions []fakeVersion, version int) models.Secret {
+	v := versions[version-1]
+	id := fakeScopeKey(scope) + "#" + key
+	return models.Secret{
+		ID:             id,
+		Environment:    scope.Environment,
+		Workspace:      scope.ProjectID,
+		SecretPath:     scope.SecretPath,
+		SecretKey:      key,
+		SecretValue:    v.value,
+		Version:        version,
+		SecretMetadata: b.metadata[id],
+	}
+}
+
+func (b *fakeBackend) ListSecrets(scope secretScope) ([]models.Secret, error) {
+	var secrets []models.Secret
+	for key, versions := range b.secrets[fakeScopeKey(scope)] {
+		secrets = append(secrets, b.secret(scope, key, versions, len(versions)))
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].SecretKey < secrets[j].SecretKey })
+	return secrets, nil
+}
+
+func (b *fakeBackend) RetrieveSecret(scope secretScope, key string, version int) (models.Secret, error) {
+	if b.failReads {
+		return models.Secret{}, fmt.Errorf("read of %s failed", key)
+	}
+	versions, ok := b.secrets[fakeScopeKey(scope)][key]
+	if !ok {
+		return models.Secret{}, fmt.Errorf("%s: %w", key, errSecretNotFound)
+	}
+	if version == 0 {
+		version = len(versions)
+	}
+	if version > len(versions) {
+		return models.Secret{}, fmt.Errorf("version %d of %s not found", version, key)
+	}
+	return b.secret(scope, key, versions, version), nil
+}
+
+func (b *fakeBackend) ListSecretVersions(secret models.Secret) ([]secretVersion, error) {
+	scope := secretScope{ProjectID: secret.Workspace, Environment: secret.Environment, SecretPath: secret.SecretPath}
+	versions := b.secrets[fakeScopeKey(scope)][secret.SecretKey]
+	history := make([]secretVersion, len(versions))
+	for i, v := range versions {
+		history[i] = secretVersion{Version: i + 1, CreatedAt: v.createdAt, Actor: v.actor, Value: v.value}
+	}
+	return history, nil
+}
+
+func (b *fakeBackend) CreateSecret(scope secretScope, key, value string) (models.Secret, error) {
+	if b.failKeys[key] {
+		return models.Secret{}, fmt.Errorf("write of %s failed", key)
+	}
+	if _, ok := b.secrets[fakeScopeKey(scope)][key]; ok {
+		return models.Secret{}, fmt.Errorf("secret %s already exists", key)
+	}
+	b.set(scope, key, value)
+	return b.secret(scope, key, b.secrets[fakeScopeKey(scope)][key], 1), nil
+}
+
+func (b *fakeBackend) BatchCreateSecrets(scope secretScope, entries []secretEntry) ([]models.Secret, error) {
+	// Like Infisical, the batch is rejected as a whole if any key exists
+	for _, e := range entries {
+		if b.failKeys[e.Key] {
+			return nil, fmt.Errorf("write of %s failed", e.Key)
+		}
+		if _, ok := b.secrets[fakeScopeKey(scope)][e.Key]; ok {
+			return nil, fmt.Errorf("secret %s already exists", e.Key)
+		}
+	}
+	secrets := make([]models.Secret, len(entries))
+	for i, e := range entries {
+		b.set(scope, e.Key, e.Value)
+		secrets[i] = b.secret(scope, e.Key, b.secrets[fakeScopeKey(scope)][e.Key], 1)
+	}
+	return secrets, nil
+}
+
+func (b *fakeBackend) UpdateSecret(scope secretScope, key, value string) (models.Secret, error) {
+	if b.failKeys[key] {
+		return models.Secret{}, fmt.Errorf("write of %s failed", key)
+	}
+	versions, ok := b.secrets[fakeScopeKey(scope)][key]
+	if !ok {
+		return models.Secret{}, fmt.Errorf("secret %s not found", key)
+	}
+	b.set(scope, key, value)
+	return b.secret(scope, key, b.secrets[fakeScopeKey(scope)][key], len(versions)+1), nil
+}
+
+func (b *fakeBackend) UpdateSecretMetadata(scope secretScope, key string, metadata []models.SecretMetadata) (models.Secret, error) {
+	secret, err := b.RetrieveSecret(scope, key, 0)
+	if err != nil {
+		return models.Secret{}, err
+	}
+	b.metadata[secret.ID] = metadata
+	secret.SecretMetadata = metadata
+	return secret, nil
+}
+
+func (b *fakeBackend) DeleteSecret(scope secretScope, key string) error {
+	if b.failKeys[key] {
+		return fmt.Errorf("delete of %s failed", key)
+	}
+	secret, err := b.RetrieveSecret(scope, key, 0)
+	if err != nil {
+		return err
+	}
+	delete(b.secrets[fakeScopeKey(scope)], key)
+	delete(b.metadata, secret.ID)
+	return nil
+}
+
+func (b *fakeBackend) ListFolders(scope secretScope) ([]models.Folder, error) {
+	var folders []models.Folder
+	for _, folder := range b.folders[fakeScopeKey(scope)] {
+		folders = append(folders, folder)
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
+	return folders, nil
+}
+
+func (b *fakeBackend) CreateFolder(scope secretScope, name string) (models.Folder, error) {
+	k := fakeScopeKey(scope)
+	if _, ok := b.folders[k][name]; ok {
+		return models.Folder{}, fmt.Errorf("folder %s already exists", name)
+	}
+	if b.folders[k] == nil {
+		b.folders[k] = make(map[string]models.Folder)
+	}
+	b.now = b.now.Add(time.Hour)
+	folder := models.Folder{ID: k + "/" + name, Name: name, Version: 1, CreatedAt: b.now, UpdatedAt: b.now}
+	b.folders[k][name] = folder
+	return folder, nil
+}
+
+func (b *fakeBackend) RenameFolder(scope secretScope, name, newName string) (models.Folder, error) {
+	k := fakeScopeKey(scope)
+	folder, ok := b.folders[k][name]
+	if !ok {
+		return models.Folder{}, fmt.Errorf("folder %s not found", name)
+	}
+	if _, exists := b.folders[k][newName]; exists {
+		return models.Folder{}, fmt.Errorf("folder %s already exists", newName)
+	}
+	delete(b.folders[k], name)
+	folder.Name, folder.Version = newName, folder.Version+1
+	b.folders[k][newName] = folder
+
+	// Move the contents of the folder subtree to the new path
+	oldPrefix := fakeScopeKey(secretScope{ProjectID: scope.ProjectID, Environment: scope.Environment, SecretPath: path.Join(scope.SecretPath, name)})
+	newPrefix := fakeScopeKey(secretScope{ProjectID: scope.ProjectID, Environment: scope.Environment, SecretPath: path.Join(scope.SecretPath, newName)})
+	for key, secrets := range b.secrets {
+		if key == oldPrefix || strings.HasPrefix(key, oldPrefix+"/") {
+			delete(b.secrets, key)
+			b.secrets[newPrefix+strings.TrimPrefix(key, oldPrefix)] = secrets
+		}
+	}
+	for key, folders := range b.folders {
+		if key == oldPrefix || strings.HasPrefix(key, oldPrefix+"/") {
+			delete(b.folders, key)
+			b.folders[newPrefix+strings.TrimPrefix(key, oldPrefix)] = folders
+		}
+	}
+	return folder, nil
+}
+
+func (b *fakeBackend) DeleteFolder(scope secretScope, name string) error {
+	k := fakeScopeKey(scope)
+	if _, ok := b.folders[k][name]; !ok {
+		return fmt.Errorf("folder %s not found", name)
+	}
+	delete(b.folders[k], name)
+	prefix := fakeScopeKey(secretScope{ProjectID: scope.ProjectID, Environment: scope.Environment, SecretPath: path.Join(scope.SecretPath, name)})
+	for key := range b.secrets {
+		if key == prefix || strings.HasPrefix(key, prefix+"/") {
+			delete(b.secrets, key)
+		}
+	}
+	for key := range b.folders {
+		if key == prefix || strings.HasPrefix(key, prefix+"/") {
+			delete(b.folders, key)
+		}
+	}
+	return nil
+}
+
+func (b *fakeBackend) ListSecretsBySource(scope secretScope) ([]models.Secret, []models.SecretImport, error) {
+	local, _ := b.ListSecrets(scope)
+	var imported []models.SecretImport
+	for _, imp := range b.imports[fakeScopeKey(scope)] {
+		source := secretScope{ProjectID: scope.ProjectID, Environment: imp.Environment, SecretPath: imp.SecretPath}
+		secrets, _ := b.ListSecrets(source)
+		imported = append(imported, models.SecretImport{Environment: imp.Environment, SecretPath: imp.SecretPath, Secrets: secrets})
+	}
+	return local, imported, nil
+}
+
+func (b *fakeBackend) ListSecretImports(scope secretScope) ([]secretImport, error) {
+	return append([]secretImport{}, b.imports[fakeScopeKey(scope)]...), nil
+}
+
+func (b *fakeBackend) CreateSecretImport(scope secretScope, source secretImport) (secretImport, error) {
+	k := fakeScopeKey(scope)
+	source.ID = fmt.Sprintf("import-%d", len(b.imports[k])+1)
+	source.Position = len(b.imports[k]) + 1
+	b.imports[k] = append(b.imports[k], source)
+	return source, nil
+}
+
+func (b *fakeBackend) DeleteSecretImport(scope secretScope, id string) error {
+	k := fakeScopeKey(scope)
+	for i, imp := range b.imports[k] {
+		if imp.ID == id {
+			b.imports[k] = append(b.imports[k][:i], b.imports[k][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("secret import %s not found", id)
+}
+
+func (b *fakeBackend) ListProjects() ([]project, error) {
+	return b.projects, nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// folderNamePattern restricts folder names to those Infisical accepts
+var folderNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// maxFolderDepth bounds recursive folder listings
+const maxFolderDepth = 16
+
+// folderOptions is the CreateAction, UpdateAction and DeleteAction property for
+// folder management. Folders are addressed by name within the target secretPath.
+type folderOptions struct {
+	Name string `json:"name"`
+	// NewName renames the folder (UpdateAction)
+	NewName string `json:"newName,omitempty"`
+	// Force deletes a folder that still contains secrets or subfolders (DeleteAction)
+	Force bool `json:"force,omitempty"`
+}
+
+// validate checks the folder name and, for renames, the new name
+func (o *folderOptions) validate(rename bool) error {
+	if !folderNamePattern.MatchString(o.Name) {
+		return fmt.Errorf("folder.name must be 1-64 letters, digits, '_' or '-'")
+	}
+	if rename && !folderNamePattern.MatchString(o.NewName) {
+		return fmt.Errorf("folder.newName must be 1-64 letters, digits, '_' or '-'")
+	}
+	return nil
+}
+
+// foldersOptions is the RetrieveAction property requesting a folder listing
+type foldersOptions struct {
+	// Recursive lists the whole folder tree below the target path
+	Recursive bool `json:"recursive,omitempty"`
+}
+
+// folderEntry is one folder of a listing
+type folderEntry struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+// childScope returns the scope of a subfolder
+func childScope(scope secretScope, name string) secretScope {
+	return secretScope{ProjectID: scope.ProjectID, Environment: scope.Environment, SecretPath: path.Join(scope.SecretPath, name)}
+}
+
+// listFolders lists the subfolders of a scope, depth first when recursive
+func listFolders(backend secretBackend, scope secretScope, recursive bool) ([]folderEntry, error) {
+	return appendFolders(nil, backend, scope, recursive, 0)
+}
+
+func appendFolders(entries []folderEntry, backend secretBackend, scope secretScope, recursive bool, depth int) ([]folderEntry, error) {
+	if depth >= maxFolderDepth {
+		return nil, fmt.Errorf("folder tree below %s is deeper than %d levels", scope.SecretPath, maxFolderDepth)
+	}
+	folders, err := backend.ListFolders(scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, folder := range folders {
+		child := childScope(scope, folder.Name)
+		entries = append(entries, folderEntry{Name: folder.Name, Path: child.SecretPath, ID: folder.ID, CreatedAt: folder.CreatedAt, UpdatedAt: folder.UpdatedAt})
+		if recursive {
+			if entries, err = appendFolders(entries, backend, child, true, depth+1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entries, nil
+}
+
+// folderContents counts the secrets and subfolders directly inside a folder
+func folderContents(backend secretBackend, folder secretScope) (int, int, error) {
+	secrets, err := backend.ListSecrets(folder)
+	if err != nil {
+		return 0, 0, err
+	}
+	folders, err := backend.ListFolders(folder)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(secrets), len(folders), nil
+}
+
+// deleteFolder deletes a subfolder of scope and returns how many secrets and
+// folders it directly contained. Non-empty folders are only deleted with force.
+func deleteFolder(backend secretBackend, scope secretScope, name string, force bool) (int, int, error) {
+	child := childScope(scope, name)
+	secrets, folders, err := folderContents(backend, child)
+	if err != nil {
+		return 0, 0, err
+	}
+	if (secrets > 0 || folders > 0) && !force {
+		return secrets, folders, fmt.Errorf("%s contains %d secrets and %d folders; set force to delete it with its contents", child.SecretPath, secrets, folders)
+	}
+	return secrets, folders, backend.DeleteFolder(scope, name)
+}
+
+// handleListFolders lists the folders below the target path
+func handleListFolders(c echo.Context, action *semantic.SemanticAction, opts *foldersOptions) error {
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	folders, err := listFolders(backend, scope, opts.Recursive)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to list folders", err)
+	}
+	log.Printf("Listed %d folders (%s, recursive=%v)", len(folders), scope, opts.Recursive)
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"path":    scope.SecretPath,
+			"folders": append([]folderEntry{}, folders...),
+		},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// handleCreateFolder creates a folder in the target path
+func handleCreateFolder(c echo.Context, action *semantic.SemanticAction, opts *folderOptions) error {
+	if err := opts.validate(false); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid folder options", err)
+	}
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	folder, err := backend.CreateFolder(scope, opts.Name)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to create folder", err)
+	}
+
+	child := childScope(scope, opts.Name)
+	log.Printf("Created folder %s (%s)", child.SecretPath, scope)
+	auditEvent("folder.created", map[string]interface{}{
+		"projectId":   scope.ProjectID,
+		"environment": scope.Environment,
+		"path":        child.SecretPath,
+	})
+	return folderResult(c, action, folderEntry{Name: folder.Name, Path: child.SecretPath, ID: folder.ID, CreatedAt: folder.CreatedAt, UpdatedAt: folder.UpdatedAt})
+}
+
+// handleRenameFolder renames a folder in the target path
+func handleRenameFolder(c echo.Context, action *semantic.SemanticAction, opts *folderOptions) error {
+	if err := opts.validate(true); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid folder options", err)
+	}
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	folder, err := backend.RenameFolder(scope, opts.Name, opts.NewName)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to rename folder", err)
+	}
+
+	from, to := childScope(scope, opts.Name), childScope(scope, opts.NewName)
+	log.Printf("Renamed folder %s to %s (%s)", from.SecretPath, to.SecretPath, scope)
+	auditEvent("folder.renamed", map[string]interface{}{
+		"projectId":   scope.ProjectID,
+		"environment": scope.Environment,
+		"path":        from.SecretPath,
+		"newPath":     to.SecretPath,
+	})
+	return folderResult(c, action, folderEntry{Name: folder.Name, Path: to.SecretPath, ID: folder.ID, CreatedAt: folder.CreatedAt, UpdatedAt: folder.UpdatedAt})
+}
+
+// handleDeleteFolder deletes a folder in the target path. Folders that still
+// contain secrets or subfolders are only deleted with force.
+func handleDeleteFolder(c echo.Context, action *semantic.SemanticAction, opts *folderOptions) error {
+	if err := opts.validate(false); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid folder options", err)
+	}
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+
+	child := childScope(scope, opts.Name)
+	secrets, folders, err := deleteFolder(backend, scope, opts.Name, opts.Force)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to delete folder", err)
+	}
+
+	log.Printf("Deleted folder %s (%s, force=%v)", child.SecretPath, scope, opts.Force)
+	auditEvent("folder.deleted", map[string]interface{}{
+		"projectId":   scope.ProjectID,
+		"environment": scope.Environment,
+		"path":        child.SecretPath,
+		"force":       opts.Force,
+		"secrets":     secrets,
+		"folders":     folders,
+	})
+	return folderResult(c, action, map[string]interface{}{
+		"path":           child.SecretPath,
+		"deleted":        true,
+		"secretsRemoved": secrets,
+		"foldersRemoved": folders,
+	})
+}
+
+func folderResult(c echo.Context, action *semantic.SemanticAction, value interface{}) error {
+	action.Result = &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value:  value,
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// FolderRequest is the body of the REST folder create and rename endpoints
+type FolderRequest struct {
+	Name        string `json:"name,omitempty"`
+	NewName     string `json:"newName,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	ProjectID   string `json:"projectId,omitempty"`
+	// Path is the parent folder (default /)
+	Path string `json:"path,omitempty"`
+}
+
+// listFoldersREST handles REST GET /v1/api/folders
+func listFoldersREST(c echo.Context) error {
+	recursive, _ := strconv.ParseBool(c.QueryParam("recursive"))
+
+	// Convert to JSON-LD RetrieveAction with folders options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"folders":  foldersOptions{Recursive: recursive},
+	}
+	addRESTTarget(action, c.QueryParam("projectId"), c.QueryParam("environment"), c.QueryParam("path"))
+
+	return callSemanticHandler(c, action)
+}
+
+// createFolderREST handles REST POST /v1/api/folders
+func createFolderREST(c echo.Context) error {
+	var req FolderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD CreateAction with folder options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "CreateAction",
+		"folder":   folderOptions{Name: req.Name},
+	}
+	addRESTTarget(action, req.ProjectID, req.Environment, req.Path)
+
+	return callSemanticHandler(c, action)
+}
+
+// renameFolderREST handles REST PATCH /v1/api/folders/:name
+func renameFolderREST(c echo.Context) error {
+	var req FolderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD UpdateAction with folder options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "UpdateAction",
+		"folder":   folderOptions{Name: c.Param("name"), NewName: req.NewName},
+	}
+	addRESTTarget(action, req.ProjectID, req.Environment, req.Path)
+
+	return callSemanticHandler(c, action)
+}
+
+// deleteFolderREST handles REST DELETE /v1/api/folders/:name
+func deleteFolderREST(c echo.Context) error {
+	force, _ := strconv.ParseBool(c.QueryParam("force"))
+
+	// Convert to JSON-LD DeleteAction with folder options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "DeleteAction",
+		"folder":   folderOptions{Name: c.Param("name"), Force: force},
+	}
+	addRESTTarget(action, c.QueryParam("projectId"), c.QueryParam("environment"), c.QueryParam("path"))
+
+	return callSemanticHandler(c, action)
+}
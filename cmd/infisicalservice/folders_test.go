@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestFolders_ListRenameDelete(t *testing.T) {
+	root := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.CreateFolder(root, "s3")
+	backend.CreateFolder(root, "db")
+	backend.CreateFolder(childScope(root, "db"), "replicas")
+	backend.set(secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/db/replicas"}, "REPLICA_HOST", "r1")
+
+	flat, err := listFolders(backend, root, false)
+	if err != nil || len(flat) != 2 {
+		t.Fatalf("Expected two top-level folders, got %+v, %v", flat, err)
+	}
+	tree, _ := listFolders(backend, root, true)
+	var paths []string
+	for _, f := range tree {
+		paths = append(paths, f.Path)
+	}
+	if len(paths) != 3 || paths[0] != "/db" || paths[1] != "/db/replicas" || paths[2] != "/s3" {
+		t.Errorf("Unexpected recursive listing: %v", paths)
+	}
+
+	if _, err := backend.RenameFolder(root, "db", "database"); err != nil {
+		t.Fatal(err)
+	}
+	moved := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/database/replicas"}
+	if _, err := backend.RetrieveSecret(moved, "REPLICA_HOST", 0); err != nil {
+		t.Error("Expected secrets to move with a renamed folder")
+	}
+
+	if _, folders, err := deleteFolder(backend, root, "database", false); err == nil || folders != 1 {
+		t.Fatalf("Expected a non-empty folder to be protected, got %d folders, %v", folders, err)
+	}
+	if tree, _ := listFolders(backend, root, true); len(tree) != 3 {
+		t.Error("A refused delete must not remove anything")
+	}
+	if _, _, err := deleteFolder(backend, root, "s3", false); err != nil {
+		t.Errorf("Expected an empty folder to be deleted, got %v", err)
+	}
+	if _, _, err := deleteFolder(backend, root, "database", true); err != nil {
+		t.Errorf("Expected a forced delete to succeed, got %v", err)
+	}
+	if _, err := backend.RetrieveSecret(moved, "REPLICA_HOST", 0); err == nil {
+		t.Error("A forced delete must remove the folder contents")
+	}
+}
+
+func TestFolderOptions_Validation(t *testing.T) {
+	for _, name := range []string{"", "a/b", "..", "has space"} {
+		if err := (&folderOptions{Name: name}).validate(false); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+	if err := (&folderOptions{Name: "s3"}).validate(true); err == nil {
+		t.Error("Expected a rename without newName to be rejected")
+	}
+	if err := (&folderOptions{Name: "s3", NewName: "storage_v2"}).validate(true); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
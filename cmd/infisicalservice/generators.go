@@ -0,0 +1,352 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+	"unicode/utf8"
+)
+
+// Generator types
+const (
+	generatorPassword    = "password"
+	generatorToken       = "token"
+	generatorUUID        = "uuid"
+	generatorHex         = "hex"
+	generatorEd25519     = "ed25519"
+	generatorRSA         = "rsa"
+	generatorCertificate = "certificate"
+)
+
+// Suffixes of the companion secrets written next to generated keypairs and certificates
+const (
+	publicKeySuffix  = "_PUBLIC_KEY"
+	privateKeySuffix = "_PRIVATE_KEY"
+)
+
+// Password character classes
+var passwordClasses = map[string]string{
+	"lower":   "abcdefghijklmnopqrstuvwxyz",
+	"upper":   "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"digits":  "0123456789",
+	"symbols": "!#$%&()*+,-./:;<=>?@[]^_{|}~",
+}
+
+// generatorPolicy describes how to generate a secret value server-side
+type generatorPolicy struct {
+	// Type is one of password, token, uuid, hex, ed25519, rsa, certificate
+	Type string `json:"type"`
+	// Length is the password length (default 32), or the number of random bytes for token and hex (default 32)
+	Length int `json:"length,omitempty"`
+	// Classes are the password character classes: lower, upper, digits, symbols (default: all)
+	Classes []string `json:"classes,omitempty"`
+	// Symbols overrides the symbol characters used by the symbols class
+	Symbols string `json:"symbols,omitempty"`
+	// Bits is the RSA key size (default 3072)
+	Bits int `json:"bits,omitempty"`
+	// KeyType is the certificate key type: ecdsa (default), ed25519 or rsa
+	KeyType string `json:"keyType,omitempty"`
+	// CommonName is the certificate subject common name (default: the secret key)
+	CommonName string `json:"commonName,omitempty"`
+	// DNSNames are the certificate subject alternative names
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// ValidDays is the certificate validity in days (default 365)
+	ValidDays int `json:"validDays,omitempty"`
+}
+
+// generatedSecret is one value produced by a generator
+type generatedSecret struct {
+	Key   string
+	Value string
+}
+
+// generatedSecrets is the output of a generator: the values to store plus
+// metadata that is safe to return to the caller
+type generatedSecrets struct {
+	Secrets  []generatedSecret
+	Metadata map[string]interface{}
+}
+
+// validate checks the policy and applies defaults
+func (p *generatorPolicy) validate() error {
+	switch p.Type {
+	case generatorPassword:
+		if p.Length == 0 {
+			p.Length = 32
+		}
+		if p.Length < 8 || p.Length > 4096 {
+			return fmt.Errorf("password length must be between 8 and 4096")
+		}
+		if len(p.Classes) == 0 {
+			p.Classes = []string{"lower", "upper", "digits", "symbols"}
+		}
+		if !utf8.ValidString(p.Symbols) {
+			return fmt.Errorf("password symbols must be valid UTF-8")
+		}
+		if len(p.Classes) > p.Length {
+			return fmt.Errorf("password length must be at least the number of character classes")
+		}
+		for _, class := range p.Classes {
+			if _, ok := passwordClasses[class]; !ok {
+				return fmt.Errorf("unknown password character class %q", class)
+			}
+		}
+	case generatorToken, generatorHex:
+		if p.Length == 0 {
+			p.Length = 32
+		}
+		if p.Length < 16 || p.Length > 1024 {
+			return fmt.Errorf("%s length must be between 16 and 1024 bytes", p.Type)
+		}
+	case generatorUUID, generatorEd25519:
+	case generatorRSA:
+		if p.Bits == 0 {
+			p.Bits = 3072
+		}
+		if p.Bits < 2048 || p.Bits > 8192 {
+			return fmt.Errorf("rsa bits must be between 2048 and 8192")
+		}
+	case generatorCertificate:
+		switch p.KeyType {
+		case "":
+			p.KeyType = "ecdsa"
+		case "ecdsa", generatorEd25519, generatorRSA:
+		default:
+			return fmt.Errorf("unsupported certificate keyType %q", p.KeyType)
+		}
+		if p.KeyType == generatorRSA {
+			if p.Bits == 0 {
+				p.Bits = 3072
+			}
+			if p.Bits < 2048 || p.Bits > 8192 {
+				return fmt.Errorf("certificate rsa bits must be between 2048 and 8192")
+			}
+		}
+		if p.ValidDays == 0 {
+			p.ValidDays = 365
+		}
+		if p.ValidDays < 1 || p.ValidDays > 3650 {
+			return fmt.Errorf("certificate validDays must be between 1 and 3650")
+		}
+	case "":
+		return fmt.Errorf("generate.type is required")
+	default:
+		return fmt.Errorf("unsupported generator type %q", p.Type)
+	}
+	return nil
+}
+
+// generate produces the secret(s) for key according to the policy
+func (p *generatorPolicy) generate(key string) (*generatedSecrets, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{"generator": p.Type}
+	single := func(value string, err error) (*generatedSecrets, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &generatedSecrets{Secrets: []generatedSecret{{Key: key, Value: value}}, Metadata: metadata}, nil
+	}
+
+	switch p.Type {
+	case generatorPassword:
+		metadata["length"] = p.Length
+		metadata["classes"] = p.Classes
+		return single(generatePassword(p.Length, p.Classes, p.Symbols))
+	case generatorToken:
+		metadata["bytes"] = p.Length
+		return single(randomString(p.Length, base64.RawURLEncoding.EncodeToString))
+	case generatorHex:
+		metadata["bytes"] = p.Length
+		return single(randomString(p.Length, hex.EncodeToString))
+	case generatorUUID:
+		return single(generateUUID())
+	case generatorEd25519, generatorRSA:
+		priv, err := generateKey(p.Type, p.Bits)
+		if err != nil {
+			return nil, err
+		}
+		privPEM, pubPEM, fingerprint, err := encodeKeyPair(priv)
+		if err != nil {
+			return nil, err
+		}
+		metadata["fingerprint"] = fingerprint
+		metadata["publicKeySecret"] = key + publicKeySuffix
+		return &generatedSecrets{
+			Secrets: []generatedSecret{
+				{Key: key, Value: privPEM},
+				{Key: key + publicKeySuffix, Value: pubPEM},
+			},
+			Metadata: metadata,
+		}, nil
+	case generatorCertificate:
+		return p.generateCertificate(key, metadata)
+	}
+	return nil, fmt.Errorf("unsupported generator type %q", p.Type)
+}
+
+// generateCertificate creates a self-signed X.509 certificate stored under key,
+// with its private key stored under key + "_PRIVATE_KEY"
+func (p *generatorPolicy) generateCertificate(key string, metadata map[string]interface{}) (*generatedSecrets, error) {
+	priv, err := generateKey(p.KeyType, p.Bits)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	commonName := p.CommonName
+	if commonName == "" {
+		commonName = key
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              p.DNSNames,
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.AddDate(0, 0, p.ValidDays),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	if _, ok := priv.(*rsa.PrivateKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	privPEM, _, _, err := encodeKeyPair(priv)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+
+	metadata["keyType"] = p.KeyType
+	metadata["commonName"] = commonName
+	metadata["notAfter"] = template.NotAfter.UTC().Format(time.RFC3339)
+	metadata["fingerprint"] = "SHA256:" + hex.EncodeToString(sum[:])
+	metadata["privateKeySecret"] = key + privateKeySuffix
+	return &generatedSecrets{
+		Secrets: []generatedSecret{
+			{Key: key, Value: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))},
+			{Key: key + privateKeySuffix, Value: privPEM},
+		},
+		Metadata: metadata,
+	}, nil
+}
+
+// generatePassword draws length characters uniformly, with at least one from every class
+func generatePassword(length int, classes []string, symbols string) (string, error) {
+	// Characters are picked as runes so multi-byte symbols stay valid UTF-8
+	sets := make([][]rune, len(classes))
+	var alphabet []rune
+	for i, class := range classes {
+		sets[i] = []rune(passwordClasses[class])
+		if class == "symbols" && symbols != "" {
+			sets[i] = []rune(symbols)
+		}
+		alphabet = append(alphabet, sets[i]...)
+	}
+
+	password := make([]rune, length)
+	for i := range password {
+		// The first characters cover each class; the rest come from the full alphabet
+		set := alphabet
+		if i < len(sets) {
+			set = sets[i]
+		}
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		password[i] = c
+	}
+
+	// Shuffle so the guaranteed class characters are not always at the start
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
+
+func randomChar(set []rune) (rune, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+// generateUUID returns a random (version 4) UUID
+func generateUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// generateKey creates a private key of the given type
+func generateKey(keyType string, bits int) (crypto.Signer, error) {
+	switch keyType {
+	case generatorEd25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	case generatorRSA:
+		return rsa.GenerateKey(rand.Reader, bits)
+	case "ecdsa":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", keyType)
+}
+
+// encodeKeyPair returns the PKCS#8 private key PEM, PKIX public key PEM and the
+// SHA-256 fingerprint of the public key
+func encodeKeyPair(priv crypto.Signer) (string, string, string, error) {
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return "", "", "", err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		return "", "", "", err
+	}
+	sum := sha256.Sum256(pubDER)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})),
+		"SHA256:" + hex.EncodeToString(sum[:]),
+		nil
+}
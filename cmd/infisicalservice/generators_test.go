@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGeneratePassword_ClassesAndLength(t *testing.T) {
+	policy := generatorPolicy{Type: generatorPassword, Length: 12, Classes: []string{"upper", "digits"}}
+	for i := 0; i < 50; i++ {
+		generated, err := policy.generate("DB_PASSWORD")
+		if err != nil {
+			t.Fatalf("generate failed: %v", err)
+		}
+		value := generated.Secrets[0].Value
+		if len(value) != 12 {
+			t.Fatalf("Expected length 12, got %d", len(value))
+		}
+		if !regexp.MustCompile(`^[A-Z0-9]+$`).MatchString(value) {
+			t.Fatalf("Password %q contains characters outside the requested classes", value)
+		}
+		if !strings.ContainsAny(value, passwordClasses["upper"]) || !strings.ContainsAny(value, passwordClasses["digits"]) {
+			t.Fatalf("Password %q is missing a requested class", value)
+		}
+	}
+}
+
+func TestGeneratePassword_MultiByteSymbols(t *testing.T) {
+	policy := generatorPolicy{Type: generatorPassword, Length: 64, Classes: []string{"symbols"}, Symbols: "€§"}
+	generated, err := policy.generate("DB_PASSWORD")
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	value := generated.Secrets[0].Value
+	if !utf8.ValidString(value) || utf8.RuneCountInString(value) != 64 || strings.Trim(value, "€§") != "" {
+		t.Fatalf("Expected 64 symbols from the custom set, got %q", value)
+	}
+}
+
+func TestGeneratorPolicy_Validation(t *testing.T) {
+	invalid := []generatorPolicy{
+		{},
+		{Type: "mnemonic"},
+		{Type: generatorPassword, Length: 4},
+		{Type: generatorPassword, Classes: []string{"emoji"}},
+		{Type: generatorPassword, Symbols: "\xff"},
+		{Type: generatorToken, Length: 8},
+		{Type: generatorRSA, Bits: 1024},
+		{Type: generatorCertificate, KeyType: "dsa"},
+		{Type: generatorCertificate, KeyType: generatorRSA, Bits: 1024},
+		{Type: generatorCertificate, KeyType: generatorRSA, Bits: 1000000},
+	}
+	for _, p := range invalid {
+		if err := p.validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", p)
+		}
+	}
+}
+
+func TestGenerate_FormatsAndCompanions(t *testing.T) {
+	uuid, err := (&generatorPolicy{Type: generatorUUID}).generate("ID")
+	if err != nil || !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid.Secrets[0].Value) {
+		t.Errorf("Unexpected uuid %+v, %v", uuid, err)
+	}
+	hexKey, err := (&generatorPolicy{Type: generatorHex, Length: 16}).generate("KEY")
+	if err != nil || !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(hexKey.Secrets[0].Value) {
+		t.Errorf("Unexpected hex key %+v, %v", hexKey, err)
+	}
+
+	keypair, err := (&generatorPolicy{Type: generatorEd25519}).generate("SIGNING_KEY")
+	if err != nil {
+		t.Fatalf("ed25519 failed: %v", err)
+	}
+	if len(keypair.Secrets) != 2 || keypair.Secrets[1].Key != "SIGNING_KEY_PUBLIC_KEY" {
+		t.Fatalf("Expected private key and public key companion, got %d secrets", len(keypair.Secrets))
+	}
+	if block, _ := pem.Decode([]byte(keypair.Secrets[1].Value)); block == nil || block.Type != "PUBLIC KEY" {
+		t.Error("Expected a PEM public key companion")
+	}
+
+	cert, err := (&generatorPolicy{Type: generatorCertificate, DNSNames: []string{"svc.internal"}, ValidDays: 30}).generate("TLS_CERT")
+	if err != nil {
+		t.Fatalf("certificate failed: %v", err)
+	}
+	block, _ := pem.Decode([]byte(cert.Secrets[0].Value))
+	if block == nil {
+		t.Fatal("Expected a PEM certificate")
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Invalid certificate: %v", err)
+	}
+	if parsed.Subject.CommonName != "TLS_CERT" || len(parsed.DNSNames) != 1 || parsed.CheckSignature(parsed.SignatureAlgorithm, parsed.RawTBSCertificate, parsed.Signature) != nil {
+		t.Errorf("Unexpected self-signed certificate: CN=%s SANs=%v", parsed.Subject.CommonName, parsed.DNSNames)
+	}
+	if cert.Secrets[1].Key != "TLS_CERT_PRIVATE_KEY" {
+		t.Errorf("Expected private key companion, got %s", cert.Secrets[1].Key)
+	}
+}
+
+func TestStoreGenerated_CreateAndRegenerate(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	backend := newFakeBackend()
+	policy := generatorPolicy{Type: generatorEd25519}
+
+	first, err := policy.generate("SIGNING_KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storeGenerated(backend, scope, first, true); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	if _, err := storeGenerated(backend, scope, first, true); err == nil {
+		t.Error("Expected create to fail when the key already exists")
+	}
+
+	second, _ := policy.generate("SIGNING_KEY")
+	versions, err := storeGenerated(backend, scope, second, false)
+	if err != nil {
+		t.Fatalf("regenerate failed: %v", err)
+	}
+	if versions[0] != 2 || versions[1] != 2 {
+		t.Errorf("Expected both keys at version 2, got %v", versions)
+	}
+
+	result := generatedResult(second, versions, false)
+	for _, entry := range result.Value.(map[string]interface{})["secrets"].([]map[string]interface{}) {
+		if _, ok := entry["value"]; ok {
+			t.Error("Generated values must not be returned unless requested")
+		}
+	}
+}
+
+func TestStoreGenerated_RestoresOnCompanionFailure(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	backend := newFakeBackend()
+	policy := generatorPolicy{Type: generatorEd25519}
+	first, _ := policy.generate("SIGNING_KEY")
+	if _, err := storeGenerated(backend, scope, first, true); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+
+	// A failed companion write restores the previous private key
+	backend.failKeys["SIGNING_KEY_PUBLIC_KEY"] = true
+	second, _ := policy.generate("SIGNING_KEY")
+	if _, err := storeGenerated(backend, scope, second, false); err == nil {
+		t.Fatal("Expected the companion write to fail")
+	}
+	current, _ := backend.RetrieveSecret(scope, "SIGNING_KEY", 0)
+	if current.SecretValue != first.Secrets[0].Value {
+		t.Error("Expected the previous private key to be restored")
+	}
+
+	// ... and deletes a newly created one
+	third, _ := policy.generate("OTHER_KEY")
+	backend.failKeys["OTHER_KEY_PUBLIC_KEY"] = true
+	if _, err := storeGenerated(backend, scope, third, true); err == nil {
+		t.Fatal("Expected the companion write to fail")
+	}
+	if _, err := backend.RetrieveSecret(scope, "OTHER_KEY", 0); !errors.Is(err, errSecretNotFound) {
+		t.Errorf("Expected the created private key to be deleted, got %v", err)
+	}
+
+	// A failed read is not mistaken for a missing companion
+	backend.failKeys = map[string]bool{}
+	backend.failReads = true
+	fourth, _ := policy.generate("SIGNING_KEY")
+	if _, err := storeGenerated(backend, scope, fourth, false); err == nil {
+		t.Fatal("Expected a failed read to abort")
+	}
+	backend.failReads = false
+	if current, _ := backend.RetrieveSecret(scope, "SIGNING_KEY", 0); current.SecretValue != first.Secrets[0].Value {
+		t.Error("Nothing must be written when reading the current keys fails")
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Import formats
+const (
+	importFormatDotenv = "dotenv"
+	importFormatJSON   = "json"
+	importFormatYAML   = "yaml"
+)
+
+// importKeyPattern restricts imported keys to names Infisical and shells accept
+var importKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]{0,254}$`)
+
+// secretEntry is a key/value pair read from an import document
+type secretEntry struct {
+	Key   string
+	Value string
+	// Line is the source line of the entry (0 for JSON)
+	Line int
+}
+
+// parseImport parses content in the given format; an empty format is detected from the content
+func parseImport(format, content string) ([]secretEntry, error) {
+	if format == "" {
+		format = detectImportFormat(content)
+	}
+	switch format {
+	case importFormatDotenv, "env":
+		return parseDotenv(content)
+	case importFormatJSON:
+		return parseJSONObject(content)
+	case importFormatYAML, "yml":
+		return parseYAMLMapping(content)
+	}
+	return nil, fmt.Errorf("unsupported import format %q (use dotenv, json or yaml)", format)
+}
+
+// detectImportFormat guesses the format: a JSON object, KEY=value lines, or YAML
+func detectImportFormat(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") {
+		return importFormatJSON
+	}
+	for _, line := range strings.Split(trimmed, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		eq, colon := strings.Index(line, "="), strings.Index(line, ":")
+		if eq > 0 && (colon < 0 || eq < colon) {
+			return importFormatDotenv
+		}
+		return importFormatYAML
+	}
+	return importFormatDotenv
+}
+
+// parseDotenv parses KEY=value lines. It supports comments, an optional
+// "export " prefix, single-quoted literals and double-quoted values with
+// escapes that may span lines.
+func parseDotenv(content string) ([]secretEntry, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	var entries []secretEntry
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNo)
+		}
+		key := strings.TrimSpace(line[:eq])
+		raw := strings.TrimSpace(line[eq+1:])
+
+		var value string
+		switch {
+		case strings.HasPrefix(raw, `"`):
+			// Collect continuation lines until the closing quote
+			for !hasClosingQuote(raw[1:], '"') && i+1 < len(lines) {
+				i++
+				raw += "\n" + lines[i]
+			}
+			end := closingQuote(raw[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated double-quoted value", lineNo)
+			}
+			unquoted, err := unescapeDoubleQuoted(raw[1 : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			value = unquoted
+			if rest := strings.TrimSpace(raw[end+2:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected text after closing quote", lineNo)
+			}
+		case strings.HasPrefix(raw, "'"):
+			end := strings.Index(raw[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single-quoted value", lineNo)
+			}
+			value = raw[1 : end+1]
+		default:
+			// Unquoted values end at an inline comment
+			if idx := strings.Index(raw, " #"); idx >= 0 {
+				raw = raw[:idx]
+			}
+			value = strings.TrimSpace(raw)
+		}
+		entries = append(entries, secretEntry{Key: key, Value: value, Line: lineNo})
+	}
+	return entries, nil
+}
+
+// parseJSONObject parses a flat JSON object of string, number, boolean or null values
+func parseJSONObject(content string) ([]secretEntry, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("JSON import must be an object of key/value pairs")
+	}
+
+	// Decode token by token to keep the document order of keys
+	var entries []secretEntry
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		key := token.(string)
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON value for %s: %w", key, err)
+		}
+
+		var value string
+		switch trimmed := bytes.TrimSpace(raw); {
+		case bytes.Equal(trimmed, []byte("null")):
+		case trimmed[0] == '"':
+			if err := json.Unmarshal(trimmed, &value); err != nil {
+				return nil, fmt.Errorf("invalid JSON value for %s: %w", key, err)
+			}
+		case trimmed[0] == '{' || trimmed[0] == '[':
+			return nil, fmt.Errorf("value of %s must be a string, number or boolean, not a nested structure", key)
+		default:
+			value = string(trimmed)
+		}
+		entries = append(entries, secretEntry{Key: key, Value: value})
+	}
+	return entries, nil
+}
+
+// parseYAMLMapping parses a YAML document holding a single flat mapping of
+// scalars; nested collections are rejected. Values keep their source text, so
+// unquoted scalars such as yes, 0755 or 1.10 are imported verbatim, and entries
+// keep the document order.
+func parseYAMLMapping(content string) ([]secretEntry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: YAML import must be a mapping of key/value pairs", mapping.Line)
+	}
+
+	entries := make([]secretEntry, 0, len(mapping.Content)/2)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+		if key.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: YAML keys must be scalars", key.Line)
+		}
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: value of %s must be a scalar, not a nested structure", key.Line, key.Value)
+		}
+		entry := secretEntry{Key: key.Value, Line: key.Line}
+		if value.Tag != "!!null" {
+			entry.Value = value.Value
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// closingQuote returns the index of the first unescaped quote in s, or -1
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func hasClosingQuote(s string, quote byte) bool {
+	return closingQuote(s, quote) >= 0
+}
+
+// unescapeDoubleQuoted decodes the backslash escapes of a double-quoted value
+func unescapeDoubleQuoted(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(s, "\n", `\n`) + `"`)
+	if err != nil {
+		return "", fmt.Errorf("invalid escape sequence in quoted value")
+	}
+	return unquoted, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	content := `# comment
+export PLAIN=value # trailing comment
+EMPTY=
+SINGLE='literal $HOME # not a comment'
+DOUBLE="line1\nline2 \"quoted\""
+MULTI="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+URL=https://example.com/a#fragment
+`
+	entries, err := parseImport("", content)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := []secretEntry{
+		{Key: "PLAIN", Value: "value", Line: 2},
+		{Key: "EMPTY", Value: "", Line: 3},
+		{Key: "SINGLE", Value: "literal $HOME # not a comment", Line: 4},
+		{Key: "DOUBLE", Value: "line1\nline2 \"quoted\"", Line: 5},
+		{Key: "MULTI", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----", Line: 6},
+		{Key: "URL", Value: "https://example.com/a#fragment", Line: 9},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want[i], entries[i])
+		}
+	}
+
+	if _, err := parseDotenv("NOT A PAIR"); err == nil {
+		t.Error("Expected error for a line without '='")
+	}
+}
+
+func TestParseDotenv_RepositoryTemplate(t *testing.T) {
+	content, err := os.ReadFile("../../infisical-secrets-template.env")
+	if err != nil {
+		t.Skipf("template not available: %v", err)
+	}
+	entries, err := parseImport("", string(content))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	found := false
+	for _, e := range entries {
+		if e.Key == "HETZNER_S3_BUCKET" && e.Value == "px-semantic" {
+			found = true
+		}
+	}
+	if len(entries) < 10 || !found {
+		t.Errorf("Unexpected template entries (%d)", len(entries))
+	}
+}
+
+func TestParseJSONObject(t *testing.T) {
+	entries, err := parseImport("", `{"B_KEY": "b", "A_PORT": 5432, "ENABLED": true, "UNSET": null}`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(entries) != 4 || entries[0].Key != "B_KEY" || entries[1].Value != "5432" || entries[2].Value != "true" || entries[3].Value != "" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+	if _, err := parseJSONObject(`{"NESTED": {"a": 1}}`); err == nil {
+		t.Error("Expected error for nested JSON values")
+	}
+	if _, err := parseJSONObject(`["a"]`); err == nil {
+		t.Error("Expected error for a JSON array")
+	}
+}
+
+func TestParseImport_YAML(t *testing.T) {
+	content := `---
+# database
+DB_HOST: db.internal   # primary
+DB_PASSWORD: "p@ss: \"word\""
+DB_PORT: 5432
+FILE_MODE: 0755
+VERSION: 1.10
+ENABLED: yes
+TIMEOUT: 1e3
+CA_CERT: |
+  -----BEGIN CERTIFICATE-----
+  MIIB
+  -----END CERTIFICATE-----
+EMPTY: ~
+`
+	entries, err := parseImport(importFormatYAML, content)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := []secretEntry{
+		{Key: "DB_HOST", Value: "db.internal", Line: 3},
+		{Key: "DB_PASSWORD", Value: `p@ss: "word"`, Line: 4},
+		{Key: "DB_PORT", Value: "5432", Line: 5},
+		{Key: "FILE_MODE", Value: "0755", Line: 6},
+		{Key: "VERSION", Value: "1.10", Line: 7},
+		{Key: "ENABLED", Value: "yes", Line: 8},
+		{Key: "TIMEOUT", Value: "1e3", Line: 9},
+		{Key: "CA_CERT", Value: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n", Line: 10},
+		{Key: "EMPTY", Value: "", Line: 14},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), entries)
+	}
+	for i, e := range entries {
+		if e != want[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want[i], e)
+		}
+	}
+
+	for _, invalid := range []string{"db:\n  host: x\n", "- a\n- b\n", "KEY: [1, 2]\n", "KEY: 'open\n"} {
+		if _, err := parseImport(importFormatYAML, invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	infisical "github.com/infisical/go-sdk"
+	sdkerrors "github.com/infisical/go-sdk/packages/errors"
+	"github.com/infisical/go-sdk/packages/models"
+)
+
+// infisicalBackend implements secretBackend with the Infisical Go SDK, calling
+// the REST API directly for operations the SDK does not cover
+type infisicalBackend struct {
+	client      infisical.InfisicalClientInterface
+	siteURL     string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// newInfisicalBackend creates an SDK client and authenticates with Universal Auth
+func newInfisicalBackend(conn infisicalConnection) (*infisicalBackend, error) {
+	// Extract host from URL (remove https:// prefix)
+	host := strings.TrimPrefix(conn.URL, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	siteURL := "https://" + strings.TrimSuffix(host, "/")
+
+	// Create Infisical client
+	client := infisical.NewInfisicalClient(context.Background(), infisical.Config{
+		SiteUrl:          siteURL,
+		AutoTokenRefresh: false,
+	})
+
+	// Authenticate
+	credential, err := client.Auth().UniversalAuthLogin(conn.ClientID, conn.ClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return &infisicalBackend{
+		client:      client,
+		siteURL:     siteURL,
+		accessToken: credential.AccessToken,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (b *infisicalBackend) ListSecrets(scope secretScope) ([]models.Secret, error) {
+	secrets, err := b.client.Secrets().List(infisical.ListSecretsOptions{
+		AttachToProcessEnv: false,
+		Environment:        scope.Environment,
+		ProjectID:          scope.ProjectID,
+		SecretPath:         scope.SecretPath,
+		IncludeImports:     scope.IncludeImports,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+func (b *infisicalBackend) RetrieveSecret(scope secretScope, key string, version int) (models.Secret, error) {
+	secret, err := b.client.Secrets().Retrieve(infisical.RetrieveSecretOptions{
+		SecretKey:      key,
+		Environment:    scope.Environment,
+		ProjectID:      scope.ProjectID,
+		SecretPath:     scope.SecretPath,
+		IncludeImports: scope.IncludeImports,
+		Version:        version,
+	})
+	if err != nil {
+		var apiErr *sdkerrors.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			err = fmt.Errorf("%w: %w", errSecretNotFound, err)
+		}
+		return models.Secret{}, fmt.Errorf("failed to retrieve secret: %w", err)
+	}
+	return secret, nil
+}
+
+func (b *infisicalBackend) CreateSecret(scope secretScope, key, value string) (models.Secret, error) {
+	secret, err := b.client.Secrets().Create(infisical.CreateSecretOptions{
+		SecretKey:   key,
+		SecretValue: value,
+		ProjectID:   scope.ProjectID,
+		Environment: scope.Environment,
+		SecretPath:  scope.SecretPath,
+	})
+	if err != nil {
+		return models.Secret{}, fmt.Errorf("failed to create secret: %w", err)
+	}
+	return secret, nil
+}
+
+func (b *infisicalBackend) BatchCreateSecrets(scope secretScope, entries []secretEntry) ([]models.Secret, error) {
+	batch := make([]infisical.BatchCreateSecret, len(entries))
+	for i, e := range entries {
+		batch[i] = infisical.BatchCreateSecret{SecretKey: e.Key, SecretValue: e.Value}
+	}
+	secrets, err := b.client.Secrets().Batch().Create(infisical.BatchCreateSecretsOptions{
+		ProjectID:   scope.ProjectID,
+		Environment: scope.Environment,
+		SecretPath:  scope.SecretPath,
+		Secrets:     batch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to batch create secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+func (b *infisicalBackend) UpdateSecret(scope secretScope, key, value string) (models.Secret, error) {
+	secret, err := b.client.Secrets().Update(infisical.UpdateSecretOptions{
+		SecretKey:      key,
+		ProjectID:      scope.ProjectID,
+		Environment:    scope.Environment,
+		SecretPath:     scope.SecretPath,
+		NewSecretValue: value,
+	})
+	if err != nil {
+		return models.Secret{}, fmt.Errorf("failed to update secret: %w", err)
+	}
+	return secret, nil
+}
+
+func (b *infisicalBackend) UpdateSecretMetadata(scope secretScope, key string, metadata []models.SecretMetadata) (models.Secret, error) {
+	// The SDK update call does not expose metadata, so use the raw endpoint
+	body := map[string]interface{}{
+		"workspaceId":    scope.ProjectID,
+		"environment":    scope.Environment,
+		"secretPath":     scope.SecretPath,
+		"secretMetadata": metadata,
+	}
+	var res struct {
+		Secret models.Secret `json:"secret"`
+	}
+	if err := b.apiRequest(http.MethodPatch, "/api/v3/secrets/raw/"+url.PathEscape(key), nil, body, &res); err != nil {
+		return models.Secret{}, fmt.Errorf("failed to update secret metadata: %w", err)
+	}
+	return res.Secret, nil
+}
+
+func (b *infisicalBackend) DeleteSecret(scope secretScope, key string) error {
+	_, err := b.client.Secrets().Delete(infisical.DeleteSecretOptions{
+		SecretKey:   key,
+		ProjectID:   scope.ProjectID,
+		Environment: scope.Environment,
+		SecretPath:  scope.SecretPath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+	return nil
+}
+
+func (b *infisicalBackend) ListFolders(scope secretScope) ([]models.Folder, error) {
+	folders, err := b.client.Folders().List(infisical.ListFoldersOptions{
+		ProjectID:   scope.ProjectID,
+		Environment: scope.Environment,
+		Path:        scope.SecretPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folders: %w", err)
+	}
+	return folders, nil
+}
+
+func (b *infisicalBackend) CreateFolder(scope secretScope, name string) (models.Folder, error) {
+	folder, err := b.client.Folders().Create(infisical.CreateFolderOptions{
+		ProjectID:   scope.ProjectID,
+		Environment: scope.Environment,
+		Path:        scope.SecretPath,
+		Name:        name,
+	})
+	if err != nil {
+		return models.Folder{}, fmt.Errorf("failed to create folder: %w", err)
+	}
+	return folder, nil
+}
+
+func (b *infisicalBackend) RenameFolder(scope secretScope, name, newName string) (models.Folder, error) {
+	// The update endpoint addresses folders by ID
+	folders, err := b.ListFolders(scope)
+	if err != nil {
+		return models.Folder{}, err
+	}
+	for _, folder := range folders {
+		if folder.Name != name {
+			continue
+		}
+		renamed, err := b.client.Folders().Update(infisical.UpdateFolderOptions{
+			FolderID:    folder.ID,
+			ProjectID:   scope.ProjectID,
+			Environment: scope.Environment,
+			Path:        scope.SecretPath,
+			NewName:     newName,
+		})
+		if err != nil {
+			return models.Folder{}, fmt.Errorf("failed to rename folder: %w", err)
+		}
+		return renamed, nil
+	}
+	return models.Folder{}, fmt.Errorf("folder %s not found", name)
+}
+
+func (b *infisicalBackend) DeleteFolder(scope secretScope, name string) error {
+	_, err := b.client.Folders().Delete(infisical.DeleteFolderOptions{
+		FolderName:  name,
+		ProjectID:   scope.ProjectID,
+		Environment: scope.Environment,
+		Path:        scope.SecretPath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+	return nil
+}
+
+func (b *infisicalBackend) ListSecretsBySource(scope secretScope) ([]models.Secret, []models.SecretImport, error) {
+	// The SDK merges imported secrets into one list, so read the raw response
+	query := url.Values{
+		"workspaceId":            {scope.ProjectID},
+		"environment":            {scope.Environment},
+		"secretPath":             {scope.SecretPath},
+		"include_imports":        {"true"},
+		"expandSecretReferences": {"false"},
+	}
+	var res struct {
+		Secrets []models.Secret       `json:"secrets"`
+		Imports []models.SecretImport `json:"imports"`
+	}
+	if err := b.apiRequest(http.MethodGet, "/api/v3/secrets/raw", query, nil, &res); err != nil {
+		return nil, nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return res.Secrets, res.Imports, nil
+}
+
+// infisicalSecretImport is a secret import as returned by the API
+type infisicalSecretImport struct {
+	ID         string `json:"id"`
+	ImportPath string `json:"importPath"`
+	ImportEnv  struct {
+		Slug string `json:"slug"`
+	} `json:"importEnv"`
+	Position int `json:"position"`
+}
+
+func (i infisicalSecretImport) secretImport() secretImport {
+	return secretImport{ID: i.ID, Environment: i.ImportEnv.Slug, SecretPath: i.ImportPath, Position: i.Position}
+}
+
+func (b *infisicalBackend) ListSecretImports(scope secretScope) ([]secretImport, error) {
+	query := url.Values{
+		"workspaceId": {scope.ProjectID},
+		"environment": {scope.Environment},
+		"path":        {scope.SecretPath},
+	}
+	var res struct {
+		SecretImports []infisicalSecretImport `json:"secretImports"`
+	}
+	if err := b.apiRequest(http.MethodGet, "/api/v1/secret-imports", query, nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to list secret imports: %w", err)
+	}
+	imports := make([]secretImport, len(res.SecretImports))
+	for i, imp := range res.SecretImports {
+		imports[i] = imp.secretImport()
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Position < imports[j].Position })
+	return imports, nil
+}
+
+func (b *infisicalBackend) CreateSecretImport(scope secretScope, source secretImport) (secretImport, error) {
+	body := map[string]interface{}{
+		"workspaceId": scope.ProjectID,
+		"environment": scope.Environment,
+		"path":        scope.SecretPath,
+		"import": map[string]string{
+			"environment": source.Environment,
+			"path":        source.SecretPath,
+		},
+	}
+	var res struct {
+		SecretImport infisicalSecretImport `json:"secretImport"`
+	}
+	if err := b.apiRequest(http.MethodPost, "/api/v1/secret-imports", nil, body, &res); err != nil {
+		return secretImport{}, fmt.Errorf("failed to create secret import: %w", err)
+	}
+	created := res.SecretImport.secretImport()
+	// The create response does not always expand the source environment
+	if created.Environment == "" {
+		created.Environment = source.Environment
+	}
+	return created, nil
+}
+
+func (b *infisicalBackend) DeleteSecretImport(scope secretScope, id string) error {
+	body := map[string]string{
+		"workspaceId": scope.ProjectID,
+		"environment": scope.Environment,
+		"path":        scope.SecretPath,
+	}
+	if err := b.apiRequest(http.MethodDelete, "/api/v1/secret-imports/"+url.PathEscape(id), nil, body, nil); err != nil {
+		return fmt.Errorf("failed to delete secret import: %w", err)
+	}
+	return nil
+}
+
+func (b *infisicalBackend) ListProjects() ([]project, error) {
+	var res struct {
+		Workspaces []struct {
+			ID           string               `json:"id"`
+			Name         string               `json:"name"`
+			Slug         string               `json:"slug"`
+			Environments []projectEnvironment `json:"environments"`
+		} `json:"workspaces"`
+	}
+	if err := b.apiRequest(http.MethodGet, "/api/v1/workspace", nil, nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	projects := make([]project, len(res.Workspaces))
+	for i, w := range res.Workspaces {
+		projects[i] = project{ID: w.ID, Name: w.Name, Slug: w.Slug, Environments: w.Environments}
+	}
+	return projects, nil
+}
+
+// apiSecretVersion is a secret version as returned by the Infisical API
+type apiSecretVersion struct {
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"createdAt"`
+	SecretValue   string    `json:"secretValue"`
+	UserActorName string    `json:"userActorName"`
+	Actor         *struct {
+		ActorType string `json:"actorType"`
+		Name      string `json:"name"`
+	} `json:"actor"`
+}
+
+func (b *infisicalBackend) ListSecretVersions(secret models.Secret) ([]secretVersion, error) {
+	const pageSize = 100
+
+	var versions []secretVersion
+	for offset := 0; ; offset += pageSize {
+		var res struct {
+			SecretVersions []apiSecretVersion `json:"secretVersions"`
+		}
+		query := url.Values{
+			"offset": {fmt.Sprint(offset)},
+			"limit":  {fmt.Sprint(pageSize)},
+		}
+		if err := b.apiRequest(http.MethodGet, "/api/v1/secret/"+url.PathEscape(secret.ID)+"/secret-versions", query, nil, &res); err != nil {
+			return nil, fmt.Errorf("failed to list secret versions: %w", err)
+		}
+
+		for _, v := range res.SecretVersions {
+			version := secretVersion{Version: v.Version, CreatedAt: v.CreatedAt, Value: v.SecretValue, Actor: v.UserActorName}
+			if v.Actor != nil {
+				version.Actor = strings.TrimSpace(v.Actor.ActorType + " " + v.Actor.Name)
+			}
+			versions = append(versions, version)
+		}
+		if len(res.SecretVersions) < pageSize {
+			break
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+// apiRequest performs an authenticated request against the Infisical REST API
+func (b *infisicalBackend) apiRequest(method, path string, query url.Values, body, out interface{}) error {
+	endpoint := b.siteURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.accessToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		// Error bodies carry a message, never secret values
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, path, resp.StatusCode, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
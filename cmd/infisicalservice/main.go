@@ -6,6 +6,7 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"eve.evalgo.org/web"
 
@@ -23,9 +24,38 @@ func main() {
 	// Initialize logger
 	logger := common.ServiceLogger("infisicalservice", "1.0.0")
 
+	// Load optional service configuration file
+	cfg, err := loadServiceConfig(os.Getenv("INFISICAL_SERVICE_CONFIG"))
+	if err != nil {
+		logger.WithError(err).Error("Failed to load service configuration")
+		os.Exit(1)
+	}
+	serviceConfig = cfg
+
+	// Initialize state manager
+	sm := statemanager.New(statemanager.Config{
+		ServiceName:   "infisicalservice",
+		MaxOperations: 100,
+	})
+
+	// Every handler is wrapped with operation tracking; the state manager only
+	// ever receives redacted results
+	store := stateManagerStore{sm: sm}
+	tracker := newOperationTracker(store)
+
+	// Rotation runs are recorded in the state manager as SecretRotation operations
+	rotations = newRotationEngine(serviceConfig.Rotation.Policies, store)
+
+	// Replication runs are recorded as SecretReplication operations
+	replications = newReplicationEngine(serviceConfig.Replication, store)
+
 	// Register action handlers with the semantic action registry
 	// This allows the service to handle semantic actions without modifying switch statements
-	semantic.MustRegister("RetrieveAction", handleRetrieveAction)
+	semantic.MustRegister("CreateAction", tracker.Track(handleCreateAction))
+	semantic.MustRegister("RetrieveAction", tracker.Track(handleRetrieveAction))
+	semantic.MustRegister("UpdateAction", tracker.Track(handleUpdateAction))
+	semantic.MustRegister("DeleteAction", tracker.Track(handleDeleteAction))
+	semantic.MustRegister("TransferAction", tracker.Track(handleTransferAction))
 
 	// Create Echo instance
 	e := echo.New()
@@ -34,9 +64,16 @@ func main() {
 	web.RegisterAssets(e)
 
 	// Middleware
-	e.Use(middleware.Logger())
+	e.Use(accessLogMiddleware(os.Stdout))
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+
+	// CORS is deny-by-default; allowed origins must be configured explicitly
+	if cors, enabled := corsMiddleware(); enabled {
+		e.Use(cors)
+	}
+
+	// Client addresses come from X-Forwarded-For only behind configured trusted proxies
+	e.IPExtractor = serviceConfig.Access.IPExtractor()
 
 	// Initialize tracing (gracefully disabled if unavailable)
 	if tracer := tracing.Init(tracing.InitConfig{
@@ -67,7 +104,12 @@ func main() {
 			{
 				Method:      "POST",
 				Path:        "/v1/api/secrets",
-				Description: "Create secret (REST convenience - converts to CreateAction)",
+				Description: "Create secret with a value or a server-side generated password, token, key or certificate (REST convenience - converts to CreateAction)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secrets/import",
+				Description: "Bulk create or upsert secrets from a dotenv, JSON or YAML document with per-key report and dry-run (converts to CreateAction with import)",
 			},
 			{
 				Method:      "GET",
@@ -77,13 +119,148 @@ func main() {
 			{
 				Method:      "PUT",
 				Path:        "/v1/api/secrets/:key",
-				Description: "Update secret (REST convenience - converts to UpdateAction)",
+				Description: "Update secret value (explicit or server-side generated) and/or expiry date (REST convenience - converts to UpdateAction)",
 			},
 			{
 				Method:      "DELETE",
 				Path:        "/v1/api/secrets/:key",
 				Description: "Delete secret (REST convenience - converts to DeleteAction)",
 			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/secrets/:key/versions",
+				Description: "List secret version history without values (REST convenience - converts to RetrieveAction with history)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/secrets/:key/versions/:version",
+				Description: "Retrieve a specific secret version (REST convenience - converts to RetrieveAction with version)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secrets/promote",
+				Description: "Compare two environments or paths of a project and promote selected keys, with dry run and overwrite policy (converts to UpdateAction with promote)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secrets/compare",
+				Description: "Diff two scopes (any project, environment and path) by key, with optional salted fingerprints and never values (converts to RetrieveAction with compare)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secrets/copy",
+				Description: "Copy selected secrets to another path, environment or project (converts to TransferAction with mode copy)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secrets/move",
+				Description: "Move selected secrets, rolling back destination writes if any key fails (converts to TransferAction with mode move)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secrets/:key/rollback",
+				Description: "Roll back a secret to a prior version or timestamp, with dry-run preview (converts to UpdateAction with rollback)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secrets/rollback",
+				Description: "Roll back every secret in a path to a prior version or timestamp (converts to UpdateAction with rollback)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/folders",
+				Description: "List folders below a path (?projectId=&environment=&path=/&recursive=true) (converts to RetrieveAction with folders)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/folders",
+				Description: "Create a folder in a path (converts to CreateAction with folder)",
+			},
+			{
+				Method:      "PATCH",
+				Path:        "/v1/api/folders/:name",
+				Description: "Rename a folder (converts to UpdateAction with folder)",
+			},
+			{
+				Method:      "DELETE",
+				Path:        "/v1/api/folders/:name",
+				Description: "Delete a folder; non-empty folders require ?force=true (converts to DeleteAction with folder)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/secret-imports",
+				Description: "List the secret imports of a path with the number of secrets each provides (converts to RetrieveAction with secretImports)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/secret-imports",
+				Description: "Import the secrets of another environment and path into a path (converts to CreateAction with secretImport)",
+			},
+			{
+				Method:      "DELETE",
+				Path:        "/v1/api/secret-imports/:id",
+				Description: "Remove a secret import from a path (converts to DeleteAction with secretImport)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/render",
+				Description: "Render a configuration file template with the secrets of one or more scopes, optionally strict and sealed (converts to RetrieveAction with render)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/resolve",
+				Description: "Substitute ${secrets.X} placeholders in a JSON-LD document with secrets from one or more scopes; unresolved placeholders fail the request (converts to RetrieveAction with resolve)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/projects",
+				Description: "List the projects and environment slugs the configured identity can access (converts to RetrieveAction with discover)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/projects/:projectId",
+				Description: "Show a project's environments, with their folder trees when ?folders=true (&recursive=true) (converts to RetrieveAction with discover)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/targets/validate",
+				Description: "Check that ?projectId, &environment and &path exist before using them as a target (converts to RetrieveAction with validateTarget)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/expiring-secrets",
+				Description: "List secrets expiring within a window (?within=30d) across configured scopes, without values (converts to RetrieveAction with expiring)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/rotations",
+				Description: "List rotation policies with last rotation, next due time and last status",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/rotations/:policy/rotate",
+				Description: "Rotate a secret now according to its policy (converts to UpdateAction with rotate)",
+			},
+			{
+				Method:      "GET",
+				Path:        "/v1/api/replications",
+				Description: "List replication jobs with next due time and recent run reports",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/replications/:job/run",
+				Description: "Run a replication job now, optionally as a dry run (?dryRun=true) (converts to UpdateAction with replicate)",
+			},
+			{
+				Method:      "POST",
+				Path:        "/v1/api/unwrap",
+				Description: "Exchange a single-use wrapping token for the wrapped RetrieveAction result",
+			},
+			{
+				Method:      "GET",
+				Path:        "/metrics",
+				Description: "Rate limiting metrics in Prometheus text format (requires the API key)",
+			},
 			{
 				Method:      "GET",
 				Path:        "/health",
@@ -92,19 +269,22 @@ func main() {
 		},
 	}))
 
-	// Initialize state manager
-	sm := statemanager.New(statemanager.Config{
-		ServiceName:   "infisicalservice",
-		MaxOperations: 100,
-	})
+	// EVE API Key middleware, followed by the per-key IP allowlist and per-identity
+	// rate limiting; shared by the semantic, REST and state routes
+	apiKey := os.Getenv("INFISICAL_SERVICE_API_KEY")
+	apiKeyMiddleware := chainMiddleware(
+		evehttp.APIKeyMiddleware(apiKey),
+		serviceConfig.Access.Middleware(),
+		rateLimits.Middleware(),
+	)
 
-	// Register state endpoints
-	apiGroup := e.Group("/v1/api")
-	sm.RegisterRoutes(apiGroup)
+	// Rate limit metrics (Prometheus text format), protected by the API key
+	e.GET("/metrics", rateLimits.MetricsHandler, apiKeyMiddleware)
 
-	// EVE API Key middleware
-	apiKey := os.Getenv("INFISICAL_SERVICE_API_KEY")
-	apiKeyMiddleware := evehttp.APIKeyMiddleware(apiKey)
+	// Register state endpoints (protected by the API key like every other /v1/api route)
+	apiGroup := e.Group("/v1/api")
+	stateGroup := e.Group("/v1/api", apiKeyMiddleware)
+	sm.RegisterRoutes(stateGroup)
 
 	// Semantic action endpoint (primary interface)
 	apiGroup.POST("/semantic/action", handleSemanticAction, apiKeyMiddleware)
@@ -112,6 +292,19 @@ func main() {
 	// REST endpoints (convenience adapters that convert to semantic actions)
 	registerRESTEndpoints(apiGroup, apiKeyMiddleware)
 
+	// Response unwrapping endpoint for single-use wrapping tokens
+	apiGroup.POST("/unwrap", unwrapREST, apiKeyMiddleware)
+
+	// Expire unused wrapping tokens in the background
+	stopBackground := make(chan struct{})
+	go wrappedResponses.RunExpiry(time.Minute, stopBackground)
+
+	// Rotate secrets whose rotation policy is due
+	go rotations.Run(serviceConfig.Rotation.checkInterval, stopBackground)
+
+	// Replicate scheduled jobs between Infisical instances
+	go replications.Run(serviceConfig.Replication.checkInterval, stopBackground)
+
 	// Get port from environment or default to 8093
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -179,8 +372,8 @@ func main() {
 		logger.Infof("Starting Infisical Semantic Service on port %s", port)
 		logger.Info("Supports Infisical secrets management with Schema.org semantic types")
 		logger.Info("Environment variables:")
-		logger.Infof("  - INFISICAL_CLIENT_ID: %s", maskSecret(os.Getenv("INFISICAL_CLIENT_ID")))
-		logger.Infof("  - INFISICAL_CLIENT_SECRET: %s", maskSecret(os.Getenv("INFISICAL_CLIENT_SECRET")))
+		logger.Infof("  - INFISICAL_CLIENT_ID: %s", credentialStatus(os.Getenv("INFISICAL_CLIENT_ID")))
+		logger.Infof("  - INFISICAL_CLIENT_SECRET: %s", credentialStatus(os.Getenv("INFISICAL_CLIENT_SECRET")))
 
 		if err := e.Start(":" + port); err != nil {
 			logger.WithError(err).Error("Server error")
@@ -193,6 +386,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	close(stopBackground)
 
 	// Unregister from registry
 	if err := registry.AutoUnregister("infisicalservice"); err != nil {
@@ -206,14 +400,3 @@ func main() {
 
 	logger.Info("Server stopped")
 }
-
-// maskSecret masks a secret for logging
-func maskSecret(secret string) string {
-	if secret == "" {
-		return "<not set>"
-	}
-	if len(secret) <= 8 {
-		return "***"
-	}
-	return secret[:4] + "..." + secret[len(secret)-4:]
-}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+	"infisicalservice/seal"
+)
+
+// placeholderPattern matches ${secrets.KEY} and ${secrets.KEY:-default}, the
+// escaped form $${secrets.KEY}, and an unterminated ${secrets. at the end of a string
+var placeholderPattern = regexp.MustCompile(`\$?\$\{secrets\.([^}]*)(\}|$)`)
+
+// maxResolveDocument bounds the size of a document to resolve in bytes
+const maxResolveDocument = 1024 * 1024
+
+// resolveOptions is the RetrieveAction property substituting secrets into a JSON-LD document
+type resolveOptions struct {
+	// Document is any JSON document, typically a JSON-LD action or workflow
+	Document json.RawMessage `json:"document"`
+	// Scopes provide the secrets as for render (default: the action target)
+	Scopes []json.RawMessage `json:"scopes,omitempty"`
+}
+
+// validate checks the options
+func (o *resolveOptions) validate() error {
+	if len(o.Document) == 0 || string(o.Document) == "null" {
+		return fmt.Errorf("resolve.document is required")
+	}
+	if len(o.Document) > maxResolveDocument {
+		return fmt.Errorf("resolve.document exceeds %d bytes", maxResolveDocument)
+	}
+	if len(o.Scopes) > maxRenderScopes {
+		return fmt.Errorf("at most %d resolve scopes are allowed", maxRenderScopes)
+	}
+	return nil
+}
+
+// unresolvedPlaceholder is a placeholder that could not be substituted
+type unresolvedPlaceholder struct {
+	// Pointer is the JSON pointer of the string containing the placeholder
+	Pointer     string `json:"pointer"`
+	Placeholder string `json:"placeholder"`
+	Reason      string `json:"reason"`
+}
+
+// unresolvedError reports every placeholder that could not be substituted
+type unresolvedError struct {
+	Placeholders []unresolvedPlaceholder
+}
+
+func (e *unresolvedError) Error() string {
+	parts := make([]string, len(e.Placeholders))
+	for i, p := range e.Placeholders {
+		parts[i] = fmt.Sprintf("%s: %s %s", p.Pointer, p.Placeholder, p.Reason)
+	}
+	return fmt.Sprintf("%d unresolved placeholders: %s", len(e.Placeholders), strings.Join(parts, "; "))
+}
+
+// placeholderResolver substitutes secrets into the strings of a document
+type placeholderResolver struct {
+	secrets    map[string]string
+	resolved   int
+	unresolved []unresolvedPlaceholder
+}
+
+// resolvePlaceholders decodes a JSON document, substitutes every placeholder in
+// its string values and returns the document with the number of substitutions.
+// Object keys are left untouched. Numbers keep their original representation.
+func resolvePlaceholders(document json.RawMessage, secrets map[string]string) (interface{}, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, 0, fmt.Errorf("invalid document: %w", err)
+	}
+	r := &placeholderResolver{secrets: secrets}
+	doc = r.walk(doc, "")
+	if len(r.unresolved) > 0 {
+		return nil, 0, &unresolvedError{Placeholders: r.unresolved}
+	}
+	return doc, r.resolved, nil
+}
+
+func (r *placeholderResolver) walk(node interface{}, pointer string) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			v[key] = r.walk(v[key], pointer+"/"+escapePointerToken(key))
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = r.walk(v[i], pointer+"/"+strconv.Itoa(i))
+		}
+	case string:
+		return r.substitute(v, pointer)
+	}
+	return node
+}
+
+// substitute replaces the placeholders of one string
+func (r *placeholderResolver) substitute(s, pointer string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		fail := func(reason string) string {
+			r.unresolved = append(r.unresolved, unresolvedPlaceholder{Pointer: pointer, Placeholder: match, Reason: reason})
+			return match
+		}
+		if !strings.HasSuffix(match, "}") {
+			return fail("is not terminated")
+		}
+		name, def, hasDefault := strings.Cut(match[len("${secrets."):len(match)-1], ":-")
+		if !importKeyPattern.MatchString(name) {
+			return fail("is not a valid secret key")
+		}
+		if value := r.secrets[name]; value != "" {
+			r.resolved++
+			return value
+		}
+		if hasDefault {
+			r.resolved++
+			return def
+		}
+		if _, exists := r.secrets[name]; exists {
+			return fail("is empty and has no default")
+		}
+		return fail("is not defined in the requested scopes")
+	})
+}
+
+// escapePointerToken escapes an object key for use in a JSON pointer (RFC 6901)
+func escapePointerToken(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// handleResolve substitutes ${secrets.X} placeholders in a JSON-LD document
+func handleResolve(c echo.Context, action *semantic.SemanticAction, opts *resolveOptions, encryption *encryptionOptions, wrap *wrapOptions) error {
+	if err := opts.validate(); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid resolve options", err)
+	}
+	var recipient interface{}
+	if encryption != nil {
+		var err error
+		if recipient, err = encryption.recipientKey(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid encryption options", err)
+		}
+	}
+	if wrap != nil {
+		if _, _, err := wrap.parse(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid wrap options", err)
+		}
+	}
+
+	secrets, scopes, done, err := scopeSecrets(c, action, "resolve", opts.Scopes)
+	if done {
+		return err
+	}
+	doc, resolved, err := resolvePlaceholders(opts.Document, secrets)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to resolve placeholders", err)
+	}
+	log.Printf("Resolved %d placeholders from %d scopes (sealed=%v)", resolved, scopes, encryption != nil)
+
+	action.Result = &semantic.SemanticResult{Type: "Dataset", Format: "application/ld+json", Value: doc}
+	if encryption != nil {
+		plaintext, err := json.Marshal(doc)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to encrypt resolved document", err)
+		}
+		envelope, err := seal.Seal(recipient, plaintext)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to encrypt resolved document", err)
+		}
+		action.Result = &semantic.SemanticResult{Type: "Dataset", Format: sealedFormat, Value: envelope}
+	}
+
+	// Hand out a single-use token instead of the document if requested
+	if wrap != nil {
+		wrapped, err := wrapResult(action.Result, wrap)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to wrap resolved document", err)
+		}
+		action.Result = wrapped
+	}
+
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// ResolveRequest is the body of the REST resolve endpoint
+type ResolveRequest struct {
+	Document json.RawMessage   `json:"document"`
+	Scopes   []json.RawMessage `json:"scopes,omitempty"`
+	// RecipientPublicKey seals the resolved document to this key
+	RecipientPublicKey string `json:"recipientPublicKey,omitempty"`
+}
+
+// resolveREST handles REST POST /v1/api/resolve
+func resolveREST(c echo.Context) error {
+	var req ResolveRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD RetrieveAction with resolve options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"resolve":  resolveOptions{Document: req.Document, Scopes: req.Scopes},
+	}
+	if req.RecipientPublicKey != "" {
+		action["encryption"] = encryptionOptions{RecipientPublicKey: req.RecipientPublicKey}
+	}
+
+	return callSemanticHandler(c, action)
+}
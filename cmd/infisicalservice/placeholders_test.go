@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolvePlaceholders(t *testing.T) {
+	secrets := map[string]string{
+		"HETZNER_S3_ACCESS_KEY": "AKIA123",
+		"HETZNER_S3_SECRET_KEY": "s3cr3t",
+		"HETZNER_S3_BUCKET":     "",
+	}
+	document := json.RawMessage(`{
+		"@type": "CreateAction",
+		"retries": 3,
+		"ratio": 1.50,
+		"target": {
+			"accessKey": "${secrets.HETZNER_S3_ACCESS_KEY}",
+			"url": "s3://${secrets.HETZNER_S3_ACCESS_KEY}:${secrets.HETZNER_S3_SECRET_KEY}@host",
+			"bucket": "${secrets.HETZNER_S3_BUCKET:-default-bucket}",
+			"region": "${secrets.REGION:-fsn1}",
+			"literal": "$${secrets.HETZNER_S3_SECRET_KEY}",
+			"other": "${env.HOME}"
+		},
+		"${secrets.KEY_NAMES_ARE_NOT_RESOLVED}": ["${secrets.HETZNER_S3_SECRET_KEY}"]
+	}`)
+
+	doc, resolved, err := resolvePlaceholders(document, secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != 6 {
+		t.Errorf("Expected 6 substitutions, got %d", resolved)
+	}
+	out, _ := json.Marshal(doc)
+	for _, want := range []string{
+		`"accessKey":"AKIA123"`,
+		`"url":"s3://AKIA123:s3cr3t@host"`,
+		`"bucket":"default-bucket"`,
+		`"region":"fsn1"`,
+		`"literal":"${secrets.HETZNER_S3_SECRET_KEY}"`,
+		`"other":"${env.HOME}"`,
+		`"${secrets.KEY_NAMES_ARE_NOT_RESOLVED}":["s3cr3t"]`,
+		`"ratio":1.50`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected %s in %s", want, out)
+		}
+	}
+}
+
+func TestResolvePlaceholders_Unresolved(t *testing.T) {
+	document := json.RawMessage(`{"a/b": ["${secrets.MISSING}", "${secrets.EMPTY}"], "c": "${secrets.bad key}", "d": "x ${secrets.OPEN"}`)
+	_, _, err := resolvePlaceholders(document, map[string]string{"EMPTY": ""})
+	var unresolved *unresolvedError
+	if !errors.As(err, &unresolved) || len(unresolved.Placeholders) != 4 {
+		t.Fatalf("Expected four unresolved placeholders, got %v", err)
+	}
+	want := []unresolvedPlaceholder{
+		{Pointer: "/a~1b/0", Placeholder: "${secrets.MISSING}", Reason: "is not defined in the requested scopes"},
+		{Pointer: "/a~1b/1", Placeholder: "${secrets.EMPTY}", Reason: "is empty and has no default"},
+		{Pointer: "/c", Placeholder: "${secrets.bad key}", Reason: "is not a valid secret key"},
+		{Pointer: "/d", Placeholder: "${secrets.OPEN", Reason: "is not terminated"},
+	}
+	for i, p := range unresolved.Placeholders {
+		if p != want[i] {
+			t.Errorf("Placeholder %d: expected %+v, got %+v", i, want[i], p)
+		}
+	}
+
+	if _, _, err := resolvePlaceholders(json.RawMessage(`{"a":`), nil); err == nil {
+		t.Error("Expected an invalid document to be rejected")
+	}
+	if err := (&resolveOptions{}).validate(); err == nil {
+		t.Error("Expected a missing document to be rejected")
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"eve.evalgo.org/semantic"
+	"github.com/infisical/go-sdk/packages/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Changes between the source and destination of a promotion
+const (
+	promoteAdded     = "added"
+	promoteChanged   = "changed"
+	promoteRemoved   = "removed"
+	promoteUnchanged = "unchanged"
+)
+
+// Planned promotion actions per key
+const (
+	promoteActionCreate = "create"
+	promoteActionUpdate = "update"
+	promoteActionSkip   = "skip"
+	promoteActionNone   = "none"
+)
+
+// Outcomes of executed promotion actions
+const (
+	promoteApplied = "applied"
+	promoteFailed  = "failed"
+)
+
+// Overwrite policies for keys that differ in the destination
+const (
+	overwriteSkip   = "skip"
+	overwriteAlways = "overwrite"
+	overwriteFail   = "fail"
+)
+
+// promoteOptions promotes secrets from the action target (source) to another
+// environment and/or path of the same project
+type promoteOptions struct {
+	Destination struct {
+		Environment string `json:"environment"`
+		SecretPath  string `json:"secretPath,omitempty"`
+	} `json:"destination"`
+	keyFilter
+	// Overwrite is skip (default), overwrite or fail for keys whose destination value differs
+	Overwrite string `json:"overwrite,omitempty"`
+	// Fingerprints adds salted value hashes to the report
+	Fingerprints bool `json:"fingerprints,omitempty"`
+	DryRun       bool `json:"dryRun,omitempty"`
+}
+
+// validate checks the options and applies defaults
+func (o *promoteOptions) validate() error {
+	if o.Destination.Environment == "" {
+		return fmt.Errorf("promote.destination.environment is required")
+	}
+	switch o.Overwrite {
+	case "":
+		o.Overwrite = overwriteSkip
+	case overwriteSkip, overwriteAlways, overwriteFail:
+	default:
+		return fmt.Errorf("promote.overwrite must be skip, overwrite or fail")
+	}
+	return o.keyFilter.validate()
+}
+
+// destination returns the destination scope for a source scope
+func (o *promoteOptions) destination(source secretScope) secretScope {
+	dest := secretScope{ProjectID: source.ProjectID, Environment: o.Destination.Environment, SecretPath: o.Destination.SecretPath}
+	if dest.SecretPath == "" {
+		dest.SecretPath = source.SecretPath
+	}
+	return dest
+}
+
+// promoteEntry reports the change and planned or applied action for one key. It never contains values.
+type promoteEntry struct {
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	Action string `json:"action"`
+	// Status is applied or failed once the action has been executed
+	Status                 string `json:"status,omitempty"`
+	Version                int    `json:"version,omitempty"`
+	Reason                 string `json:"reason,omitempty"`
+	SourceFingerprint      string `json:"sourceFingerprint,omitempty"`
+	DestinationFingerprint string `json:"destinationFingerprint,omitempty"`
+}
+
+// planPromotion compares source and destination and decides the action for every selected key
+func planPromotion(backend secretBackend, source, dest secretScope, opts *promoteOptions) ([]promoteEntry, map[string]string, error) {
+	sourceSecrets, err := backend.ListSecrets(source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("source: %w", err)
+	}
+	destSecrets, err := backend.ListSecrets(dest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("destination: %w", err)
+	}
+
+	var fp *fingerprinter
+	if opts.Fingerprints {
+		if fp, err = newFingerprinter(""); err != nil {
+			return nil, nil, err
+		}
+	}
+	entries, values := planChanges(sourceSecrets, destSecrets, opts.keyFilter, opts.Overwrite, fp)
+	return entries, values, nil
+}
+
+// planChanges decides the action for every selected key of two secret lists
+// and returns the source values needed to apply them
+func planChanges(sourceSecrets, destSecrets []models.Secret, filter keyFilter, overwrite string, fp *fingerprinter) ([]promoteEntry, map[string]string) {
+	var entries []promoteEntry
+	values := make(map[string]string)
+	for _, k := range compareSecretSets(sourceSecrets, destSecrets) {
+		if !filter.matches(k.Name) {
+			continue
+		}
+		entry := promoteEntry{Name: k.Name}
+		switch {
+		case !k.InRight:
+			entry.Change, entry.Action = promoteAdded, promoteActionCreate
+		case !k.InLeft:
+			// Keys only in the destination are reported, never deleted
+			entry.Change, entry.Action = promoteRemoved, promoteActionNone
+		case k.Equal:
+			entry.Change, entry.Action = promoteUnchanged, promoteActionNone
+		case overwrite == overwriteAlways:
+			entry.Change, entry.Action = promoteChanged, promoteActionUpdate
+		default:
+			entry.Change, entry.Action = promoteChanged, promoteActionSkip
+			entry.Reason = "destination value differs (overwrite policy: " + overwrite + ")"
+		}
+		if fp != nil {
+			if k.InLeft {
+				entry.SourceFingerprint = fp.Fingerprint(k.leftValue)
+			}
+			if k.InRight {
+				entry.DestinationFingerprint = fp.Fingerprint(k.rightValue)
+			}
+		}
+		if k.InLeft {
+			values[k.Name] = k.leftValue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, values
+}
+
+// applyPromotion executes the planned create and update actions. Values that
+// fail the validation policies of the destination are not written.
+func applyPromotion(backend secretBackend, dest secretScope, entries []promoteEntry, values map[string]string) {
+	var creates []int
+	for i := range entries {
+		if entries[i].Action == promoteActionCreate || entries[i].Action == promoteActionUpdate {
+			if rejection := serviceConfig.Validation.rejection(dest, entries[i].Name, values[entries[i].Name]); rejection != "" {
+				entries[i].Status, entries[i].Reason = promoteFailed, rejection
+				continue
+			}
+		}
+		switch entries[i].Action {
+		case promoteActionCreate:
+			creates = append(creates, i)
+		case promoteActionUpdate:
+			secret, err := backend.UpdateSecret(dest, entries[i].Name, values[entries[i].Name])
+			if err != nil {
+				entries[i].Status, entries[i].Reason = promoteFailed, err.Error()
+				continue
+			}
+			entries[i].Status, entries[i].Version = promoteApplied, secret.Version
+		}
+	}
+
+	for start := 0; start < len(creates); start += importBatchSize {
+		chunk := creates[start:min(start+importBatchSize, len(creates))]
+		batch := make([]secretEntry, len(chunk))
+		for j, idx := range chunk {
+			batch[j] = secretEntry{Key: entries[idx].Name, Value: values[entries[idx].Name]}
+		}
+		created, err := backend.BatchCreateSecrets(dest, batch)
+		for _, idx := range chunk {
+			if err != nil {
+				entries[idx].Status, entries[idx].Reason = promoteFailed, err.Error()
+				continue
+			}
+			entries[idx].Status = promoteApplied
+			for _, secret := range created {
+				if secret.SecretKey == entries[idx].Name {
+					entries[idx].Version = secret.Version
+				}
+			}
+		}
+	}
+}
+
+// handlePromote compares two environments or paths of a project and copies
+// the selected differences from the action target to the destination
+func handlePromote(c echo.Context, action *semantic.SemanticAction, opts *promoteOptions) error {
+	if err := opts.validate(); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid promote options", err)
+	}
+
+	backend, source, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	dest := opts.destination(source)
+	if dest.Environment == source.Environment && dest.SecretPath == source.SecretPath {
+		return semantic.ReturnActionError(c, action, "Invalid promote options", fmt.Errorf("source and destination are the same"))
+	}
+
+	entries, values, err := planPromotion(backend, source, dest, opts)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to compare environments", err)
+	}
+
+	if opts.Overwrite == overwriteFail {
+		var conflicts []string
+		for _, e := range entries {
+			if e.Change == promoteChanged {
+				conflicts = append(conflicts, e.Name)
+			}
+		}
+		if len(conflicts) > 0 && !opts.DryRun {
+			return semantic.ReturnActionError(c, action, "Promotion aborted", fmt.Errorf("destination values differ for %d keys: %s", len(conflicts), strings.Join(conflicts, ", ")))
+		}
+	}
+
+	if !opts.DryRun {
+		applyPromotion(backend, dest, entries, values)
+	}
+
+	summary := map[string]int{}
+	for _, e := range entries {
+		summary[e.Change]++
+	}
+	log.Printf("Promotion %s -> %s (dryRun=%v, overwrite=%s): %v", source, dest, opts.DryRun, opts.Overwrite, summary)
+	if !opts.DryRun {
+		auditEvent("secret.promote", map[string]interface{}{
+			"projectId":         source.ProjectID,
+			"sourceEnvironment": source.Environment,
+			"sourcePath":        source.SecretPath,
+			"destEnvironment":   dest.Environment,
+			"destPath":          dest.SecretPath,
+			"overwrite":         opts.Overwrite,
+			"summary":           summary,
+		})
+	}
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"dryRun":      opts.DryRun,
+			"source":      source,
+			"destination": dest,
+			"overwrite":   opts.Overwrite,
+			"summary":     summary,
+			"entries":     entries,
+		},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// PromoteRequest is the body of the REST promotion endpoint
+type PromoteRequest struct {
+	ProjectID             string   `json:"projectId,omitempty"`
+	Environment           string   `json:"environment,omitempty"`
+	SecretPath            string   `json:"secretPath,omitempty"`
+	DestinationEnv        string   `json:"destinationEnvironment"`
+	DestinationSecretPath string   `json:"destinationSecretPath,omitempty"`
+	Keys                  []string `json:"keys,omitempty"`
+	Include               []string `json:"include,omitempty"`
+	Exclude               []string `json:"exclude,omitempty"`
+	Overwrite             string   `json:"overwrite,omitempty"`
+	Fingerprints          bool     `json:"fingerprints,omitempty"`
+	DryRun                bool     `json:"dryRun,omitempty"`
+}
+
+// promoteREST handles REST POST /v1/api/secrets/promote
+func promoteREST(c echo.Context) error {
+	var req PromoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	opts := promoteOptions{
+		keyFilter:    keyFilter{Keys: req.Keys, Include: req.Include, Exclude: req.Exclude},
+		Overwrite:    req.Overwrite,
+		Fingerprints: req.Fingerprints,
+		DryRun:       req.DryRun,
+	}
+	opts.Destination.Environment = req.DestinationEnv
+	opts.Destination.SecretPath = req.DestinationSecretPath
+
+	// Convert to JSON-LD UpdateAction with promote options; the target is the source
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "UpdateAction",
+		"promote":  opts,
+	}
+	addRESTTarget(action, req.ProjectID, req.Environment, req.SecretPath)
+
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPromotion_PlanAndApply(t *testing.T) {
+	dev := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	prod := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(dev, "API_URL", "https://api.dev")
+	backend.set(dev, "FEATURE_FLAG", "on")
+	backend.set(dev, "LOG_LEVEL", "debug")
+	backend.set(dev, "DEBUG_TOKEN", "dev-only")
+	backend.set(prod, "API_URL", "https://api.prod")
+	backend.set(prod, "LOG_LEVEL", "debug")
+	backend.set(prod, "LEGACY", "old")
+
+	opts := &promoteOptions{keyFilter: keyFilter{Exclude: []string{"DEBUG_*"}}, Fingerprints: true}
+	opts.Destination.Environment = "prod"
+	if err := opts.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, values, err := planPromotion(backend, dev, prod, opts)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	want := map[string][2]string{
+		"API_URL":      {promoteChanged, promoteActionSkip},
+		"FEATURE_FLAG": {promoteAdded, promoteActionCreate},
+		"LEGACY":       {promoteRemoved, promoteActionNone},
+		"LOG_LEVEL":    {promoteUnchanged, promoteActionNone},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries (DEBUG_TOKEN excluded), got %+v", len(want), entries)
+	}
+	for _, e := range entries {
+		if got := [2]string{e.Change, e.Action}; got != want[e.Name] {
+			t.Errorf("%s: expected %v, got %v", e.Name, want[e.Name], got)
+		}
+		if e.Name == "LOG_LEVEL" && e.SourceFingerprint != e.DestinationFingerprint {
+			t.Error("Equal values must have equal fingerprints")
+		}
+	}
+	report, _ := json.Marshal(entries)
+	if strings.Contains(string(report), "https://api") {
+		t.Fatal("Promotion report must not contain values")
+	}
+
+	applyPromotion(backend, prod, entries, values)
+	if flag, err := backend.RetrieveSecret(prod, "FEATURE_FLAG", 0); err != nil || flag.SecretValue != "on" {
+		t.Errorf("Expected FEATURE_FLAG promoted, got %v", err)
+	}
+	if url, _ := backend.RetrieveSecret(prod, "API_URL", 0); url.SecretValue != "https://api.prod" {
+		t.Error("Skip policy must not overwrite differing destination values")
+	}
+	if _, err := backend.RetrieveSecret(prod, "LEGACY", 0); err != nil {
+		t.Error("Keys only in the destination must not be deleted")
+	}
+
+	opts.Overwrite = overwriteAlways
+	opts.Keys = []string{"API_URL"}
+	entries, values, _ = planPromotion(backend, dev, prod, opts)
+	applyPromotion(backend, prod, entries, values)
+	if len(entries) != 1 || entries[0].Status != promoteApplied || entries[0].Version != 2 {
+		t.Errorf("Expected API_URL overwritten, got %+v", entries)
+	}
+}
+
+func TestPromoteOptions_Validation(t *testing.T) {
+	if err := (&promoteOptions{}).validate(); err == nil {
+		t.Error("Expected error without destination environment")
+	}
+	opts := &promoteOptions{Overwrite: "merge"}
+	opts.Destination.Environment = "prod"
+	if err := opts.validate(); err == nil {
+		t.Error("Expected error for unknown overwrite policy")
+	}
+	opts = &promoteOptions{keyFilter: keyFilter{Include: []string{"[A-"}}}
+	opts.Destination.Environment = "prod"
+	if err := opts.validate(); err == nil {
+		t.Error("Expected error for malformed pattern")
+	}
+}
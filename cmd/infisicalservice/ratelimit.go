@@ -0,0 +1,226 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Rate limit scopes, used as metric labels
+const (
+	rateLimitScopeIdentity = "identity"
+	rateLimitScopeProject  = "project"
+)
+
+// maxIdleBuckets triggers pruning of refilled buckets once exceeded
+const maxIdleBuckets = 10000
+
+// tokenBucketLimiter is a keyed token bucket rate limiter
+type tokenBucketLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens added per second
+	burst   float64 // bucket capacity
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucketLimiter creates a limiter, or nil (unlimited) when rate is not positive
+func newTokenBucketLimiter(rate float64, burst int) *tokenBucketLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+	return &tokenBucketLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token for key. When none is available it reports how long
+// until the next token is added.
+func (l *tokenBucketLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.prune(now)
+		}
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// prune drops buckets that have refilled completely and therefore carry no state
+func (l *tokenBucketLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitMetrics counts rate limit decisions per scope
+type rateLimitMetrics struct {
+	allowed  map[string]*atomic.Int64
+	rejected map[string]*atomic.Int64
+}
+
+func newRateLimitMetrics() *rateLimitMetrics {
+	m := &rateLimitMetrics{
+		allowed:  make(map[string]*atomic.Int64),
+		rejected: make(map[string]*atomic.Int64),
+	}
+	for _, scope := range []string{rateLimitScopeIdentity, rateLimitScopeProject} {
+		m.allowed[scope] = &atomic.Int64{}
+		m.rejected[scope] = &atomic.Int64{}
+	}
+	return m
+}
+
+// rateLimiter bundles the identity and project limiters with their metrics
+type rateLimiter struct {
+	identity *tokenBucketLimiter
+	project  *tokenBucketLimiter
+	metrics  *rateLimitMetrics
+}
+
+// rateLimits is the service-wide rate limiter
+var rateLimits = loadRateLimiter()
+
+// loadRateLimiter reads limits from the environment. A rate of 0 disables a scope.
+//
+//	INFISICAL_RATE_LIMIT_IDENTITY_RPS / _BURST  (default 5 / 20)
+//	INFISICAL_RATE_LIMIT_PROJECT_RPS / _BURST   (default 2 / 10)
+func loadRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		identity: newTokenBucketLimiter(
+			envFloat("INFISICAL_RATE_LIMIT_IDENTITY_RPS", 5),
+			int(envFloat("INFISICAL_RATE_LIMIT_IDENTITY_BURST", 20)),
+		),
+		project: newTokenBucketLimiter(
+			envFloat("INFISICAL_RATE_LIMIT_PROJECT_RPS", 2),
+			int(envFloat("INFISICAL_RATE_LIMIT_PROJECT_BURST", 10)),
+		),
+		metrics: newRateLimitMetrics(),
+	}
+}
+
+// envFloat reads a numeric environment variable, falling back to def when unset or invalid
+func envFloat(name string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Middleware limits requests per caller identity. It must run after the API
+// key middleware so only authenticated identities get their own bucket.
+func (r *rateLimiter) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ok, retryAfter := r.identity.Allow(callerIdentity(c))
+			if !ok {
+				return r.reject(c, rateLimitScopeIdentity, retryAfter)
+			}
+			r.metrics.allowed[rateLimitScopeIdentity].Add(1)
+			return next(c)
+		}
+	}
+}
+
+// CheckProject limits requests per target project. It returns true when the
+// request was rejected and a 429 response has been written.
+func (r *rateLimiter) CheckProject(c echo.Context, projectID string) (bool, error) {
+	ok, retryAfter := r.project.Allow(projectID)
+	if !ok {
+		return true, r.reject(c, rateLimitScopeProject, retryAfter)
+	}
+	r.metrics.allowed[rateLimitScopeProject].Add(1)
+	return false, nil
+}
+
+// reject writes a 429 response with a Retry-After header in whole seconds
+func (r *rateLimiter) reject(c echo.Context, scope string, retryAfter time.Duration) error {
+	r.metrics.rejected[scope].Add(1)
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	return c.JSON(http.StatusTooManyRequests, map[string]string{
+		"error": fmt.Sprintf("rate limit exceeded for %s, retry after %ds", scope, seconds),
+	})
+}
+
+// MetricsHandler exposes rate limit counters in the Prometheus text format
+func (r *rateLimiter) MetricsHandler(c echo.Context) error {
+	var sb strings.Builder
+	sb.WriteString("# HELP infisicalservice_ratelimit_allowed_total Requests allowed by the rate limiter.\n")
+	sb.WriteString("# TYPE infisicalservice_ratelimit_allowed_total counter\n")
+	for _, scope := range []string{rateLimitScopeIdentity, rateLimitScopeProject} {
+		fmt.Fprintf(&sb, "infisicalservice_ratelimit_allowed_total{scope=%q} %d\n", scope, r.metrics.allowed[scope].Load())
+	}
+	sb.WriteString("# HELP infisicalservice_ratelimit_rejected_total Requests rejected by the rate limiter.\n")
+	sb.WriteString("# TYPE infisicalservice_ratelimit_rejected_total counter\n")
+	for _, scope := range []string{rateLimitScopeIdentity, rateLimitScopeProject} {
+		fmt.Fprintf(&sb, "infisicalservice_ratelimit_rejected_total{scope=%q} %d\n", scope, r.metrics.rejected[scope].Load())
+	}
+	return c.String(http.StatusOK, sb.String())
+}
+
+// callerIdentity identifies the caller by a hash of its API key, falling back
+// to the client IP for unauthenticated deployments
+func callerIdentity(c echo.Context) string {
+	key := presentedAPIKey(c)
+	if key == "" {
+		return "ip:" + c.RealIP()
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "key:" + hex.EncodeToString(sum[:8])
+}
+
+// chainMiddleware combines middlewares into one, applied in the given order
+func chainMiddleware(middlewares ...echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
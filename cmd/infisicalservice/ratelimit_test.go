@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	limiter := newTokenBucketLimiter(1, 2)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow("caller"); !ok {
+			t.Fatalf("Request %d within burst should be allowed", i+1)
+		}
+	}
+	ok, retryAfter := limiter.Allow("caller")
+	if ok {
+		t.Fatal("Request beyond burst should be rejected")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("Unexpected retry-after %v", retryAfter)
+	}
+
+	if ok, _ := limiter.Allow("other-caller"); !ok {
+		t.Error("Buckets must be independent per key")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := limiter.Allow("caller"); !ok {
+		t.Error("Bucket should refill over time")
+	}
+}
+
+func TestTokenBucketLimiter_Disabled(t *testing.T) {
+	limiter := newTokenBucketLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if ok, _ := limiter.Allow("caller"); !ok {
+			t.Fatal("Disabled limiter must allow every request")
+		}
+	}
+}
+
+func TestRateLimitMiddleware_Returns429(t *testing.T) {
+	limits := &rateLimiter{
+		identity: newTokenBucketLimiter(0.5, 1),
+		metrics:  newRateLimitMetrics(),
+	}
+
+	e := echo.New()
+	e.GET("/v1/api/secrets/:key", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, limits.Middleware())
+	e.GET("/metrics", limits.MetricsHandler)
+
+	do := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/api/secrets/KEY", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("key-a"); rec.Code != http.StatusOK {
+		t.Fatalf("First request should pass, got %d", rec.Code)
+	}
+	rec := do("key-a")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected Retry-After 2, got %q", rec.Header().Get("Retry-After"))
+	}
+	if rec := do("key-b"); rec.Code != http.StatusOK {
+		t.Errorf("Other API key should have its own bucket, got %d", rec.Code)
+	}
+
+	metrics := httptest.NewRecorder()
+	e.ServeHTTP(metrics, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(metrics.Body.String(), `infisicalservice_ratelimit_rejected_total{scope="identity"} 1`) {
+		t.Errorf("Metrics should count the rejected request, got:\n%s", metrics.Body.String())
+	}
+}
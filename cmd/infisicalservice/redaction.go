@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// redactedPlaceholder replaces anything the redaction policy forbids logging
+const redactedPlaceholder = "[REDACTED]"
+
+// accessLogFormat mirrors Echo's default access log format, but logs the
+// path from accessLogPath instead of the full URI so query strings never reach the logs
+const accessLogFormat = `{"time":"${time_rfc3339_nano}","id":"${id}","remote_ip":"${remote_ip}",` +
+	`"host":"${host}","method":"${method}","path":"${custom}","user_agent":"${user_agent}",` +
+	`"status":${status},"error":"${error}","latency":${latency},"latency_human":"${latency_human}"` +
+	`,"bytes_in":${bytes_in},"bytes_out":${bytes_out}}` + "\n"
+
+// RedactionPolicy is the central policy for what secret-related data may be logged.
+// Secret values are never logged; key names only when LogKeyNames is enabled.
+type RedactionPolicy struct {
+	LogKeyNames bool
+}
+
+// redaction is the policy used by all logging in the service
+var redaction = loadRedactionPolicy()
+
+// loadRedactionPolicy reads the redaction policy from the environment
+func loadRedactionPolicy() RedactionPolicy {
+	logKeyNames, _ := strconv.ParseBool(os.Getenv("INFISICAL_LOG_SECRET_KEYS"))
+	return RedactionPolicy{LogKeyNames: logKeyNames}
+}
+
+// KeyName returns the secret key name if the policy allows logging it
+func (p RedactionPolicy) KeyName(key string) string {
+	if !p.LogKeyNames {
+		return redactedPlaceholder
+	}
+	return key
+}
+
+// credentialStatus describes a credential for logging without revealing any part of it
+func credentialStatus(value string) string {
+	if value == "" {
+		return "<not set>"
+	}
+	return "<set>"
+}
+
+// accessLogMiddleware returns an access logger that never records query strings
+func accessLogMiddleware(output io.Writer) echo.MiddlewareFunc {
+	return middleware.LoggerWithConfig(middleware.LoggerConfig{
+		Format: accessLogFormat,
+		CustomTagFunc: func(c echo.Context, buf *bytes.Buffer) (int, error) {
+			return buf.WriteString(accessLogPath(c))
+		},
+		Output: output,
+	})
+}
+
+// accessLogPath returns the path to log for a request. Paths such as
+// /v1/api/secrets/:key carry key names, so unless the policy allows key names
+// the route template is logged, and requests matching no route are redacted.
+func accessLogPath(c echo.Context) string {
+	switch {
+	case redaction.LogKeyNames:
+		return c.Request().URL.Path
+	case c.Path() == "":
+		return redactedPlaceholder
+	}
+	return c.Path()
+}
+
+// redactResult summarises an action result for storage outside the HTTP response.
+// Only the result type, format and entry count are kept, plus key names if allowed.
+func redactResult(result *semantic.SemanticResult) map[string]interface{} {
+	if result == nil {
+		return nil
+	}
+
+	summary := map[string]interface{}{
+		"type":   result.Type,
+		"format": result.Format,
+	}
+	if entries, ok := result.Value.([]interface{}); ok {
+		summary["count"] = len(entries)
+		if redaction.LogKeyNames {
+			names := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				if name := entryName(entry); name != "" {
+					names = append(names, name)
+				}
+			}
+			summary["names"] = names
+		}
+	}
+	return summary
+}
+
+// entryName extracts the "name" field of a result entry
+func entryName(entry interface{}) string {
+	switch e := entry.(type) {
+	case map[string]string:
+		return e["name"]
+	case map[string]interface{}:
+		name, _ := e["name"].(string)
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// captureLog redirects the standard logger into a buffer for the duration of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestFetchSecrets_NoValuesInLogs(t *testing.T) {
+	scope := secretScope{ProjectID: "project", Environment: "prod", SecretPath: "/"}
+	fetched := map[string]string{
+		"HETZNER_S3_ACCESS_KEY": "4JDEMZEQ4XHNCSMCTIY7",
+		"BASEX_PASSWORD":        "s3cr3t",
+		"SHORT":                 "q9",
+	}
+	backend := newFakeBackend()
+	for key, value := range fetched {
+		backend.set(scope, key, value)
+	}
+
+	for _, logKeyNames := range []bool{false, true} {
+		original := redaction
+		redaction = RedactionPolicy{LogKeyNames: logKeyNames}
+		buf := captureLog(t)
+
+		secrets, err := fetchSecrets(backend, scope)
+		redaction = original
+		if err != nil {
+			t.Fatalf("fetchSecrets returned error: %v", err)
+		}
+		if len(secrets) != len(fetched) {
+			t.Fatalf("Expected %d secrets, got %d", len(fetched), len(secrets))
+		}
+
+		output := buf.String()
+		for key, value := range fetched {
+			if strings.Contains(output, value) {
+				t.Errorf("Log output (logKeyNames=%v) contains value of %s", logKeyNames, key)
+			}
+			if strings.Contains(output, key) != logKeyNames {
+				t.Errorf("Key name %s logged=%v, want %v", key, !logKeyNames, logKeyNames)
+			}
+		}
+	}
+
+	// A full retrieval through the semantic handler must not log the
+	// credentials it connects with either
+	useFakeBackend(t, backend)
+	buf := captureLog(t)
+	action := map[string]interface{}{"@context": "https://schema.org", "@type": "RetrieveAction"}
+	addRESTTarget(action, scope.ProjectID, scope.Environment, scope.SecretPath)
+	rec := serveAction(t, action)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	output := buf.String()
+	for _, credential := range []string{"test-client-id", "test-client-secret"} {
+		if strings.Contains(output, credential) {
+			t.Errorf("Log output contains credential %q", credential)
+		}
+	}
+	for key, value := range fetched {
+		if strings.Contains(output, value) || strings.Contains(output, key) {
+			t.Errorf("Log output of the handler contains %s or its value", key)
+		}
+	}
+}
+
+func TestAccessLog_ScrubsQueryString(t *testing.T) {
+	var buf bytes.Buffer
+	e := echo.New()
+	e.Use(accessLogMiddleware(&buf))
+	e.GET("/v1/api/secrets/:key", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/api/secrets/BASEX_PASSWORD?projectId=abc&token=leaked-token", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	output := buf.String()
+	if !strings.Contains(output, `"path":"/v1/api/secrets/:key"`) {
+		t.Errorf("Access log should contain the route template, got %q", output)
+	}
+	if strings.Contains(output, "BASEX_PASSWORD") {
+		t.Errorf("Access log should not contain the key name, got %q", output)
+	}
+	if strings.Contains(output, "leaked-token") || strings.Contains(output, "projectId") {
+		t.Errorf("Access log should not contain query string, got %q", output)
+	}
+
+	// Paths matching no route are redacted as they may carry key names too
+	buf.Reset()
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/api/unknown/BASEX_PASSWORD", nil))
+	if strings.Contains(buf.String(), "BASEX_PASSWORD") {
+		t.Errorf("Access log should not contain unmatched paths, got %q", buf.String())
+	}
+
+	// Full paths are logged when key names may be logged
+	saved := redaction
+	defer func() { redaction = saved }()
+	redaction = RedactionPolicy{LogKeyNames: true}
+	buf.Reset()
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(buf.String(), `"path":"/v1/api/secrets/BASEX_PASSWORD"`) {
+		t.Errorf("Access log should contain the request path when key names are allowed, got %q", buf.String())
+	}
+}
+
+func TestCredentialStatus(t *testing.T) {
+	if got := credentialStatus(""); got != "<not set>" {
+		t.Errorf("Expected '<not set>' for empty credential, got %q", got)
+	}
+	if got := credentialStatus("17e1f6587f74254abfca3dc6f4f4fa37"); got != "<set>" {
+		t.Errorf("Expected '<set>' for configured credential, got %q", got)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+	"infisicalservice/seal"
+)
+
+const (
+	// maxRenderTemplate bounds the size of a configuration file template in bytes
+	maxRenderTemplate = 256 * 1024
+	// maxRenderOutput bounds the size of a rendered configuration file in bytes
+	maxRenderOutput = 1024 * 1024
+	// maxRenderScopes bounds the number of scopes a template is rendered with
+	maxRenderScopes = 8
+)
+
+// renderFuncs is the function set of configuration file templates: the
+// composite functions plus xml for escaping values embedded in XML
+var renderFuncs = func() template.FuncMap {
+	funcs := template.FuncMap{
+		"xml": func(s string) (string, error) {
+			var b strings.Builder
+			err := xml.EscapeText(&b, []byte(s))
+			return b.String(), err
+		},
+	}
+	for name, fn := range compositeFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}()
+
+// renderOptions is the RetrieveAction property rendering a configuration file
+// with the secrets of one or more scopes
+type renderOptions struct {
+	// Template is the text/template source of the file
+	Template string `json:"template"`
+	// Scopes provide the secrets, each an alias name or a target object with
+	// projectId, environment, secretPath and includeImports (default: the action target).
+	// Earlier scopes take precedence for keys present in several.
+	Scopes []json.RawMessage `json:"scopes,omitempty"`
+	// Strict fails on references to missing keys instead of rendering them empty
+	Strict bool `json:"strict,omitempty"`
+	// Filename and ContentType describe the rendered file (default text/plain)
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// parse validates the options and parses the template
+func (o *renderOptions) parse() (*template.Template, error) {
+	if o.Template == "" {
+		return nil, fmt.Errorf("render.template is required")
+	}
+	if len(o.Template) > maxRenderTemplate {
+		return nil, fmt.Errorf("render.template exceeds %d bytes", maxRenderTemplate)
+	}
+	if len(o.Scopes) > maxRenderScopes {
+		return nil, fmt.Errorf("at most %d render scopes are allowed", maxRenderScopes)
+	}
+	if o.ContentType == "" {
+		o.ContentType = "text/plain"
+	}
+	missingKey := "missingkey=zero"
+	if o.Strict {
+		missingKey = "missingkey=error"
+	}
+	name := o.Filename
+	if name == "" {
+		name = "template"
+	}
+	return template.New(name).Funcs(renderFuncs).Option(missingKey).Parse(o.Template)
+}
+
+// renderScope is a scope to read secrets from and the instance whose identity reads them
+type renderScope struct {
+	scope    secretScope
+	identity string
+}
+
+// resolveRenderScope resolves an alias name or a target object, filling omitted fields from the defaults
+func resolveRenderScope(raw json.RawMessage) (renderScope, error) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		alias, err := serviceConfig.Aliases.resolve(name)
+		if err != nil {
+			return renderScope{}, err
+		}
+		return renderScope{scope: alias.scope(), identity: alias.Identity}, nil
+	}
+	var scope secretScope
+	if err := json.Unmarshal(raw, &scope); err != nil {
+		return renderScope{}, fmt.Errorf("a scope must be an alias name or a target object: %w", err)
+	}
+	scope, err := serviceConfig.Defaults.apply(scope, targetSetsIncludeImports(raw))
+	return renderScope{scope: scope}, err
+}
+
+// renderData reads the secrets of every scope into one map; earlier scopes win
+func renderData(scopes []renderScope, backends func(identity string) (secretBackend, error)) (map[string]string, error) {
+	data := map[string]string{}
+	for _, s := range scopes {
+		backend, err := backends(s.identity)
+		if err != nil {
+			return nil, err
+		}
+		secrets, err := fetchSecrets(backend, s.scope)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.scope, err)
+		}
+		for _, secret := range secrets {
+			entry := secret.(map[string]string)
+			if _, exists := data[entry["name"]]; !exists {
+				data[entry["name"]] = entry["value"]
+			}
+		}
+	}
+	return data, nil
+}
+
+// scopeSecrets reads the secrets of the raw scopes of property (the action
+// target when there are none) and returns them with the number of scopes read.
+// Like actionBackend it reports whether the response has been written.
+func scopeSecrets(c echo.Context, action *semantic.SemanticAction, property string, rawScopes []json.RawMessage) (map[string]string, int, bool, error) {
+	var scopes []renderScope
+	backends := map[string]secretBackend{}
+	if len(rawScopes) == 0 {
+		backend, scope, done, err := actionBackend(c, action)
+		if done {
+			return nil, 0, true, err
+		}
+		backends[""] = backend
+		scopes = append(scopes, renderScope{scope: scope})
+	}
+	checked := map[string]bool{}
+	for i, raw := range rawScopes {
+		s, err := resolveRenderScope(raw)
+		if err != nil {
+			return nil, 0, true, semantic.ReturnActionError(c, action, "Invalid "+property+" options", fmt.Errorf("%s.scopes[%d]: %w", property, i, err))
+		}
+		if !checked[s.scope.ProjectID] {
+			checked[s.scope.ProjectID] = true
+			if limited, err := rateLimits.CheckProject(c, s.scope.ProjectID); limited {
+				return nil, 0, true, err
+			}
+		}
+		scopes = append(scopes, s)
+	}
+
+	data, err := renderData(scopes, func(identity string) (secretBackend, error) {
+		if backend, ok := backends[identity]; ok {
+			return backend, nil
+		}
+		conn, err := instanceConnection(serviceConfig.Replication.Instances, identity)
+		if err != nil {
+			return nil, err
+		}
+		backend, err := connectBackend(conn)
+		if err != nil {
+			return nil, err
+		}
+		backends[identity] = backend
+		return backend, nil
+	})
+	if err != nil {
+		return nil, 0, true, semantic.ReturnActionError(c, action, "Failed to retrieve secrets from Infisical", err)
+	}
+	return data, len(scopes), false, nil
+}
+
+// renderTemplate executes the template over data within the output limit
+func renderTemplate(tmpl *template.Template, data map[string]string) (string, error) {
+	out := &limitedBuffer{limit: maxRenderOutput}
+	if err := tmpl.Execute(out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// handleRender renders a configuration file template with the secrets of the
+// requested scopes, optionally sealed to the caller's public key
+func handleRender(c echo.Context, action *semantic.SemanticAction, opts *renderOptions, encryption *encryptionOptions, wrap *wrapOptions) error {
+	tmpl, err := opts.parse()
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid render options", err)
+	}
+	var recipient interface{}
+	if encryption != nil {
+		if recipient, err = encryption.recipientKey(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid encryption options", err)
+		}
+	}
+	if wrap != nil {
+		if _, _, err := wrap.parse(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid wrap options", err)
+		}
+	}
+
+	data, scopes, done, err := scopeSecrets(c, action, "render", opts.Scopes)
+	if done {
+		return err
+	}
+	rendered, err := renderTemplate(tmpl, data)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to render template", err)
+	}
+	log.Printf("Rendered %s (%d bytes) from %d scopes (strict=%v, sealed=%v)", tmpl.Name(), len(rendered), scopes, opts.Strict, encryption != nil)
+
+	file := map[string]interface{}{"encodingFormat": opts.ContentType}
+	if opts.Filename != "" {
+		file["name"] = opts.Filename
+	}
+	action.Result = &semantic.SemanticResult{Type: "MediaObject", Format: opts.ContentType, Value: file}
+	if encryption != nil {
+		envelope, err := seal.SealString(recipient, rendered)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to encrypt rendered file", err)
+		}
+		file["sealedText"] = envelope
+		action.Result.Format = sealedFormat
+	} else {
+		file["text"] = rendered
+	}
+
+	// Hand out a single-use token instead of the file if requested
+	if wrap != nil {
+		wrapped, err := wrapResult(action.Result, wrap)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to wrap rendered file", err)
+		}
+		action.Result = wrapped
+	}
+
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// RenderRequest is the body of the REST render endpoint
+type RenderRequest struct {
+	Template    string            `json:"template"`
+	Scopes      []json.RawMessage `json:"scopes,omitempty"`
+	Strict      bool              `json:"strict,omitempty"`
+	Filename    string            `json:"filename,omitempty"`
+	ContentType string            `json:"contentType,omitempty"`
+	// RecipientPublicKey seals the rendered file to this key
+	RecipientPublicKey string `json:"recipientPublicKey,omitempty"`
+}
+
+// renderREST handles REST POST /v1/api/render
+func renderREST(c echo.Context) error {
+	var req RenderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD RetrieveAction with render options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"render": renderOptions{
+			Template:    req.Template,
+			Scopes:      req.Scopes,
+			Strict:      req.Strict,
+			Filename:    req.Filename,
+			ContentType: req.ContentType,
+		},
+	}
+	if req.RecipientPublicKey != "" {
+		action["encryption"] = encryptionOptions{RecipientPublicKey: req.RecipientPublicKey}
+	}
+
+	return callSemanticHandler(c, action)
+}
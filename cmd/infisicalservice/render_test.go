@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const baseXConfigTemplate = `<database url="{{ .BASEX_URL }}">
+  <user>{{ xml .BASEX_USERNAME }}</user>
+  <password>{{ xml .BASEX_PASSWORD }}</password>
+  <timeout>{{ .BASEX_TIMEOUT | default "30" }}</timeout>
+</database>
+`
+
+func TestRender_ScopesAndStrictMode(t *testing.T) {
+	shared := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/shared"}
+	basex := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/basex"}
+	backend := newFakeBackend()
+	backend.set(shared, "BASEX_URL", "https://shared")
+	backend.set(shared, "BASEX_USERNAME", "shared-user")
+	backend.set(basex, "BASEX_URL", "https://basex:8984")
+	backend.set(basex, "BASEX_PASSWORD", `p<&>"`)
+
+	data, err := renderData([]renderScope{{scope: basex}, {scope: shared}}, func(string) (secretBackend, error) { return backend, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["BASEX_URL"] != "https://basex:8984" || data["BASEX_USERNAME"] != "shared-user" {
+		t.Errorf("Expected earlier scopes to win and later ones to fill gaps, got %v", data)
+	}
+
+	opts := renderOptions{Template: baseXConfigTemplate, Filename: "basex.xml"}
+	tmpl, err := opts.parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := renderTemplate(tmpl, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`url="https://basex:8984"`, "<password>p&lt;&amp;&gt;&#34;</password>", "<timeout>30</timeout>"} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("Expected %s in rendered file:\n%s", want, rendered)
+		}
+	}
+	if opts.ContentType != "text/plain" {
+		t.Errorf("Expected the default content type, got %q", opts.ContentType)
+	}
+
+	opts.Strict = true
+	tmpl, _ = opts.parse()
+	if _, err := renderTemplate(tmpl, data); err == nil || !strings.Contains(err.Error(), "BASEX_TIMEOUT") {
+		t.Errorf("Expected strict mode to name the missing key, got %v", err)
+	}
+}
+
+func TestRender_ResolveScopes(t *testing.T) {
+	saved := serviceConfig
+	defer func() { serviceConfig = saved }()
+	serviceConfig = &ServiceConfig{
+		Aliases:  AliasConfig{"iqs-basex": {ProjectID: "p-basex", SecretPath: "/"}},
+		Defaults: TargetDefaults{ProjectID: "p-default", Environment: "dev"},
+	}
+	t.Setenv(envDefaultProjectID, "")
+	t.Setenv(envDefaultEnvironment, "")
+
+	alias, err := resolveRenderScope(json.RawMessage(`"iqs-basex/prod"`))
+	if err != nil || alias.scope != (secretScope{ProjectID: "p-basex", Environment: "prod", SecretPath: "/"}) {
+		t.Errorf("Expected the alias to resolve, got %+v, %v", alias, err)
+	}
+	object, err := resolveRenderScope(json.RawMessage(`{"secretPath":"/nginx"}`))
+	if err != nil || object.scope != (secretScope{ProjectID: "p-default", Environment: "dev", SecretPath: "/nginx"}) {
+		t.Errorf("Expected defaults to fill the scope object, got %+v, %v", object, err)
+	}
+	if _, err := resolveRenderScope(json.RawMessage(`42`)); err == nil {
+		t.Error("Expected an invalid scope to be rejected")
+	}
+
+	if _, err := (&renderOptions{}).parse(); err == nil {
+		t.Error("Expected a missing template to be rejected")
+	}
+}
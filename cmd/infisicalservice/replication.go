@@ -0,0 +1,486 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// Replication run outcomes
+const (
+	replicationSucceeded = "succeeded"
+	replicationPartial   = "partial"
+	replicationFailed    = "failed"
+)
+
+// Replication triggers
+const (
+	replicationTriggerSchedule = "schedule"
+	replicationTriggerManual   = "manual"
+)
+
+const (
+	defaultReplicationCheckInterval = time.Minute
+	minReplicationInterval          = time.Minute
+	// maxReplicationHistory is the number of run reports kept per job
+	maxReplicationHistory = 20
+)
+
+// ReplicationConfig configures one-way replication between Infisical instances
+type ReplicationConfig struct {
+	// CheckInterval is how often the scheduler looks for due jobs (Go duration, default 1m)
+	CheckInterval string `json:"checkInterval,omitempty"`
+	// Instances are additional Infisical instances by name; the service's own
+	// INFISICAL_* settings are used when a job names no instance
+	Instances map[string]InfisicalInstance `json:"instances,omitempty"`
+	Jobs      []ReplicationJob             `json:"jobs,omitempty"`
+
+	checkInterval time.Duration
+}
+
+// InfisicalInstance is an Infisical instance and the machine identity used for it.
+// Credentials are read from the named environment variables, never from the file.
+type InfisicalInstance struct {
+	URL             string `json:"url"`
+	ClientIDEnv     string `json:"clientIdEnv"`
+	ClientSecretEnv string `json:"clientSecretEnv"`
+}
+
+// ReplicationEndpoint is one side of a replication job
+type ReplicationEndpoint struct {
+	// Instance names an entry of replication.instances (empty: the service's own instance)
+	Instance    string `json:"instance,omitempty"`
+	ProjectID   string `json:"projectId"`
+	Environment string `json:"environment"`
+	SecretPath  string `json:"secretPath,omitempty"`
+}
+
+// ReplicationJob mirrors the selected keys of a source scope to a destination scope
+type ReplicationJob struct {
+	Name        string              `json:"name"`
+	Source      ReplicationEndpoint `json:"source"`
+	Destination ReplicationEndpoint `json:"destination"`
+	keyFilter
+	// Overwrite is overwrite (default: the source wins) or skip (keep differing destination values)
+	Overwrite string `json:"overwrite,omitempty"`
+	// Interval runs the job on a schedule (Go duration); empty means on demand only
+	Interval string `json:"interval,omitempty"`
+
+	interval time.Duration
+}
+
+// validate checks the replication configuration and applies defaults
+func (r *ReplicationConfig) validate() error {
+	r.checkInterval = defaultReplicationCheckInterval
+	if r.CheckInterval != "" {
+		d, err := time.ParseDuration(r.CheckInterval)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("replication.checkInterval %q is not a positive duration", r.CheckInterval)
+		}
+		r.checkInterval = d
+	}
+
+	for name, instance := range r.Instances {
+		if instance.URL == "" || instance.ClientIDEnv == "" || instance.ClientSecretEnv == "" {
+			return fmt.Errorf("replication instance %q: url, clientIdEnv and clientSecretEnv are required", name)
+		}
+	}
+
+	names := map[string]bool{}
+	for i := range r.Jobs {
+		j := &r.Jobs[i]
+		if j.Name == "" {
+			return fmt.Errorf("replication.jobs[%d]: name is required", i)
+		}
+		if names[j.Name] {
+			return fmt.Errorf("replication job %q is defined twice", j.Name)
+		}
+		names[j.Name] = true
+		if err := j.validate(r.Instances); err != nil {
+			return fmt.Errorf("replication job %q: %w", j.Name, err)
+		}
+	}
+	return nil
+}
+
+func (j *ReplicationJob) validate(instances map[string]InfisicalInstance) error {
+	for _, side := range []struct {
+		name     string
+		endpoint *ReplicationEndpoint
+	}{{"source", &j.Source}, {"destination", &j.Destination}} {
+		e := side.endpoint
+		if e.ProjectID == "" || e.Environment == "" {
+			return fmt.Errorf("%s: projectId and environment are required", side.name)
+		}
+		if e.SecretPath == "" {
+			e.SecretPath = "/"
+		}
+		if _, ok := instances[e.Instance]; e.Instance != "" && !ok {
+			return fmt.Errorf("%s: unknown instance %q", side.name, e.Instance)
+		}
+	}
+	if j.Source == j.Destination {
+		return fmt.Errorf("source and destination are the same")
+	}
+
+	switch j.Overwrite {
+	case "":
+		j.Overwrite = overwriteAlways
+	case overwriteAlways, overwriteSkip:
+	default:
+		return fmt.Errorf("overwrite must be overwrite or skip")
+	}
+
+	if j.Interval != "" {
+		d, err := time.ParseDuration(j.Interval)
+		if err != nil || d < minReplicationInterval {
+			return fmt.Errorf("interval %q must be a duration of at least %s", j.Interval, minReplicationInterval)
+		}
+		j.interval = d
+	}
+	return j.keyFilter.validate()
+}
+
+// scope returns the secret scope of a replication endpoint
+func (e ReplicationEndpoint) scope() secretScope {
+	return secretScope{ProjectID: e.ProjectID, Environment: e.Environment, SecretPath: e.SecretPath}
+}
+
+// String describes the endpoint for logs and status reports
+func (e ReplicationEndpoint) String() string {
+	instance := e.Instance
+	if instance == "" {
+		instance = "default"
+	}
+	return fmt.Sprintf("instance=%s, %s", instance, e.scope())
+}
+
+// replicationRun reports one run of a job. It never contains values.
+type replicationRun struct {
+	Job        string         `json:"job"`
+	Trigger    string         `json:"trigger"`
+	DryRun     bool           `json:"dryRun,omitempty"`
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	Summary    map[string]int `json:"summary,omitempty"`
+	Entries    []promoteEntry `json:"entries,omitempty"`
+}
+
+// replicationStatus is the state of a job with its most recent runs, newest first
+type replicationStatus struct {
+	Job         string           `json:"job"`
+	Source      string           `json:"source"`
+	Destination string           `json:"destination"`
+	Interval    string           `json:"interval,omitempty"`
+	NextDue     time.Time        `json:"nextDue,omitzero"`
+	Running     bool             `json:"running"`
+	Runs        []replicationRun `json:"runs"`
+}
+
+// replicationEngine runs replication jobs on schedule and on demand
+type replicationEngine struct {
+	jobs      map[string]*ReplicationJob
+	instances map[string]InfisicalInstance
+	store     operationStore
+	connect   func(instance string) (secretBackend, error)
+	now       func() time.Time
+
+	mu     sync.Mutex
+	status map[string]*replicationStatus
+}
+
+// replications is the engine configured from the service configuration file
+var replications = newReplicationEngine(ReplicationConfig{}, nil)
+
+// newReplicationEngine creates an engine for a validated configuration.
+// Runs are recorded in store when it is not nil.
+func newReplicationEngine(cfg ReplicationConfig, store operationStore) *replicationEngine {
+	e := &replicationEngine{
+		jobs:      make(map[string]*ReplicationJob),
+		instances: cfg.Instances,
+		store:     store,
+		now:       time.Now,
+		status:    make(map[string]*replicationStatus),
+	}
+	e.connect = func(instance string) (secretBackend, error) {
+		conn, err := e.connection(instance)
+		if err != nil {
+			return nil, err
+		}
+		return connectBackend(conn)
+	}
+	for i := range cfg.Jobs {
+		j := &cfg.Jobs[i]
+		e.jobs[j.Name] = j
+		e.status[j.Name] = &replicationStatus{
+			Job:         j.Name,
+			Source:      j.Source.String(),
+			Destination: j.Destination.String(),
+			Interval:    j.Interval,
+			Runs:        []replicationRun{},
+		}
+	}
+	return e
+}
+
+// connection returns the connection settings of a named instance
+func (e *replicationEngine) connection(instance string) (infisicalConnection, error) {
+	return instanceConnection(e.instances, instance)
+}
+
+// Statuses returns the state of every job, sorted by name
+func (e *replicationEngine) Statuses() []replicationStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statuses := make([]replicationStatus, 0, len(e.status))
+	for _, s := range e.status {
+		status := *s
+		status.Runs = append([]replicationRun{}, s.Runs...)
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Job < statuses[j].Job })
+	return statuses
+}
+
+// Run starts due scheduled jobs at the given interval until stop is closed
+func (e *replicationEngine) Run(interval time.Duration, stop <-chan struct{}) {
+	if len(e.jobs) == 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultReplicationCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.RunDue()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// RunDue runs every scheduled job whose interval has elapsed and returns the names run
+func (e *replicationEngine) RunDue() []string {
+	names := make([]string, 0, len(e.jobs))
+	for name, j := range e.jobs {
+		if j.interval > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var ran []string
+	for _, name := range names {
+		e.mu.Lock()
+		due := e.now().Compare(e.status[name].NextDue) >= 0
+		e.mu.Unlock()
+		if !due {
+			continue
+		}
+		if _, err := e.runJob(name, replicationTriggerSchedule, false); err == nil {
+			ran = append(ran, name)
+		}
+	}
+	return ran
+}
+
+// RunNow runs a job immediately, regardless of its schedule
+func (e *replicationEngine) RunNow(name string, dryRun bool) (replicationRun, error) {
+	if _, ok := e.jobs[name]; !ok {
+		return replicationRun{}, fmt.Errorf("unknown replication job %q", name)
+	}
+	return e.runJob(name, replicationTriggerManual, dryRun)
+}
+
+// runJob executes one run of a job and records its report
+func (e *replicationEngine) runJob(name, trigger string, dryRun bool) (replicationRun, error) {
+	j := e.jobs[name]
+
+	e.mu.Lock()
+	status := e.status[name]
+	if status.Running {
+		e.mu.Unlock()
+		return replicationRun{}, fmt.Errorf("replication job %q is already running", name)
+	}
+	status.Running = true
+	e.mu.Unlock()
+
+	operationID := newOperationID()
+	if e.store != nil && !dryRun {
+		e.store.StartOperation(operationID, "SecretReplication", map[string]interface{}{
+			"job":     name,
+			"trigger": trigger,
+		})
+	}
+
+	run := replicationRun{Job: name, Trigger: trigger, DryRun: dryRun, StartedAt: e.now()}
+	entries, err := e.replicate(j, dryRun)
+	run.FinishedAt = e.now()
+	run.Entries = entries
+	run.Summary = map[string]int{}
+	for _, entry := range entries {
+		run.Summary[entry.Change]++
+		if entry.Status == promoteApplied {
+			run.Summary["written"]++
+		}
+		if entry.Status == promoteFailed {
+			run.Summary[promoteFailed]++
+		}
+	}
+	switch {
+	case err != nil:
+		run.Status, run.Error = replicationFailed, err.Error()
+	case run.Summary[promoteFailed] > 0:
+		run.Status = replicationPartial
+		err = fmt.Errorf("%d keys failed to replicate", run.Summary[promoteFailed])
+	default:
+		run.Status = replicationSucceeded
+	}
+
+	e.mu.Lock()
+	status.Running = false
+	if !dryRun {
+		if j.interval > 0 {
+			status.NextDue = run.StartedAt.Add(j.interval)
+		}
+		status.Runs = append([]replicationRun{run}, status.Runs...)
+		if len(status.Runs) > maxReplicationHistory {
+			status.Runs = status.Runs[:maxReplicationHistory]
+		}
+	}
+	e.mu.Unlock()
+
+	if dryRun {
+		return run, nil
+	}
+	log.Printf("Replication job %s (%s): %s %v", name, trigger, run.Status, run.Summary)
+	if e.store != nil {
+		if run.Status == replicationFailed {
+			e.store.FailOperation(operationID, err)
+		} else {
+			e.store.CompleteOperation(operationID, map[string]interface{}{
+				"job":        name,
+				"trigger":    trigger,
+				"status":     run.Status,
+				"summary":    run.Summary,
+				"durationMs": run.FinishedAt.Sub(run.StartedAt).Milliseconds(),
+			})
+		}
+	}
+	if run.Summary["written"] > 0 {
+		auditEvent("secret.replicated", map[string]interface{}{
+			"job":         name,
+			"source":      j.Source.String(),
+			"destination": j.Destination.String(),
+			"trigger":     trigger,
+			"summary":     run.Summary,
+		})
+	}
+	return run, err
+}
+
+// replicate compares source and destination and writes the keys that
+// changed. Values that already match are not written again.
+func (e *replicationEngine) replicate(j *ReplicationJob, dryRun bool) ([]promoteEntry, error) {
+	source, err := e.connect(j.Source.Instance)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	dest := source
+	if j.Destination.Instance != j.Source.Instance {
+		if dest, err = e.connect(j.Destination.Instance); err != nil {
+			return nil, fmt.Errorf("destination: %w", err)
+		}
+	}
+
+	sourceSecrets, err := source.ListSecrets(j.Source.scope())
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	destSecrets, err := dest.ListSecrets(j.Destination.scope())
+	if err != nil {
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+
+	entries, values := planChanges(sourceSecrets, destSecrets, j.keyFilter, j.Overwrite, nil)
+	if !dryRun {
+		applyPromotion(dest, j.Destination.scope(), entries, values)
+	}
+	return entries, nil
+}
+
+// replicateOptions is the UpdateAction property requesting an immediate replication run
+type replicateOptions struct {
+	Job    string `json:"job"`
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+// handleReplicateNow runs a configured replication job on demand
+func handleReplicateNow(c echo.Context, action *semantic.SemanticAction, opts *replicateOptions) error {
+	j, ok := replications.jobs[opts.Job]
+	if opts.Job == "" || !ok {
+		return semantic.ReturnActionError(c, action, "Invalid replicate options", fmt.Errorf("unknown replication job %q", opts.Job))
+	}
+	// A run reads the source and writes the destination project
+	if limited, err := rateLimits.CheckProject(c, j.Source.ProjectID); limited {
+		return err
+	}
+	if j.Destination.ProjectID != j.Source.ProjectID {
+		if limited, err := rateLimits.CheckProject(c, j.Destination.ProjectID); limited {
+			return err
+		}
+	}
+
+	run, err := replications.RunNow(opts.Job, opts.DryRun)
+	if err != nil && run.Job == "" {
+		return semantic.ReturnActionError(c, action, "Replication failed", err)
+	}
+	if run.Status == replicationFailed {
+		return semantic.ReturnActionError(c, action, "Replication failed", err)
+	}
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value:  run,
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// getReplicationsREST handles REST GET /v1/api/replications
+func getReplicationsREST(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{"jobs": replications.Statuses()})
+}
+
+// replicateNowREST handles REST POST /v1/api/replications/:job/run
+func replicateNowREST(c echo.Context) error {
+	dryRun := false
+	if raw := c.QueryParam("dryRun"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid dryRun %q: must be true or false", raw)})
+		}
+	}
+
+	// Convert to JSON-LD UpdateAction with replicate options
+	action := map[string]interface{}{
+		"@context":  "https://schema.org",
+		"@type":     "UpdateAction",
+		"replicate": replicateOptions{Job: c.Param("job"), DryRun: dryRun},
+	}
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newTestReplicationEngine creates an engine over fake backends keyed by instance name
+func newTestReplicationEngine(t *testing.T, backends map[string]*fakeBackend, store operationStore, cfg ReplicationConfig) *replicationEngine {
+	t.Helper()
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Invalid replication config: %v", err)
+	}
+	e := newReplicationEngine(cfg, store)
+	e.connect = func(instance string) (secretBackend, error) { return backends[instance], nil }
+	e.now = func() time.Time { return backends[""].now }
+	return e
+}
+
+func TestReplicationEngine_ScheduleAndChangeDetection(t *testing.T) {
+	cloud, selfHosted := newFakeBackend(), newFakeBackend()
+	source := secretScope{ProjectID: "cloud-p", Environment: "prod", SecretPath: "/"}
+	dest := secretScope{ProjectID: "onprem-p", Environment: "prod", SecretPath: "/shared"}
+	cloud.set(source, "S3_ACCESS_KEY", "AKIA1")
+	cloud.set(source, "S3_SECRET_KEY", "secret1")
+	cloud.set(source, "INTERNAL_ONLY", "x")
+	selfHosted.set(dest, "S3_SECRET_KEY", "stale")
+	selfHosted.set(dest, "LOCAL_ONLY", "y")
+
+	store := &recordingStore{}
+	engine := newTestReplicationEngine(t, map[string]*fakeBackend{"": cloud, "onprem": selfHosted}, store, ReplicationConfig{
+		Instances: map[string]InfisicalInstance{"onprem": {URL: "https://infisical.internal", ClientIDEnv: "ONPREM_ID", ClientSecretEnv: "ONPREM_SECRET"}},
+		Jobs: []ReplicationJob{{
+			Name:        "s3",
+			Source:      ReplicationEndpoint{ProjectID: "cloud-p", Environment: "prod"},
+			Destination: ReplicationEndpoint{Instance: "onprem", ProjectID: "onprem-p", Environment: "prod", SecretPath: "/shared"},
+			keyFilter:   keyFilter{Include: []string{"S3_*"}},
+			Interval:    "1h",
+		}},
+	})
+
+	if ran := engine.RunDue(); len(ran) != 1 {
+		t.Fatalf("Expected the job to run on first check, got %v", ran)
+	}
+	if v, _ := selfHosted.RetrieveSecret(dest, "S3_ACCESS_KEY", 0); v.SecretValue != "AKIA1" {
+		t.Error("Expected S3_ACCESS_KEY created in the destination")
+	}
+	if v, _ := selfHosted.RetrieveSecret(dest, "S3_SECRET_KEY", 0); v.SecretValue != "secret1" || v.Version != 2 {
+		t.Errorf("Expected the source to win the conflict, got v%d", v.Version)
+	}
+	if _, err := selfHosted.RetrieveSecret(dest, "INTERNAL_ONLY", 0); err == nil {
+		t.Error("Filtered keys must not be replicated")
+	}
+
+	if ran := engine.RunDue(); len(ran) != 0 {
+		t.Fatalf("Job ran before its interval elapsed: %v", ran)
+	}
+	cloud.now = cloud.now.Add(2 * time.Hour)
+	if ran := engine.RunDue(); len(ran) != 1 {
+		t.Fatalf("Expected the due job to run, got %v", ran)
+	}
+	status := engine.Statuses()[0]
+	if len(status.Runs) != 2 || status.Runs[0].Status != replicationSucceeded {
+		t.Fatalf("Expected two successful runs, got %+v", status.Runs)
+	}
+	if status.Runs[0].Summary["written"] != 0 || status.Runs[0].Summary[promoteUnchanged] != 2 {
+		t.Errorf("Unchanged values must not be written again, got %v", status.Runs[0].Summary)
+	}
+	if v, _ := selfHosted.RetrieveSecret(dest, "S3_SECRET_KEY", 0); v.Version != 2 {
+		t.Errorf("Expected no new destination version, got v%d", v.Version)
+	}
+	if len(store.completed) != 2 {
+		t.Errorf("Expected two completed operations, got %d", len(store.completed))
+	}
+}
+
+func TestReplicationEngine_DryRunAndSkip(t *testing.T) {
+	backend := newFakeBackend()
+	source := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	dest := secretScope{ProjectID: "p", Environment: "dr", SecretPath: "/"}
+	backend.set(source, "A", "1")
+	backend.set(source, "B", "2")
+	backend.set(dest, "B", "local")
+
+	engine := newTestReplicationEngine(t, map[string]*fakeBackend{"": backend}, nil, ReplicationConfig{
+		Jobs: []ReplicationJob{{
+			Name:        "dr",
+			Source:      ReplicationEndpoint{ProjectID: "p", Environment: "prod"},
+			Destination: ReplicationEndpoint{ProjectID: "p", Environment: "dr"},
+			Overwrite:   overwriteSkip,
+		}},
+	})
+
+	run, err := engine.RunNow("dr", true)
+	if err != nil || run.Summary[promoteAdded] != 1 || run.Summary[promoteChanged] != 1 {
+		t.Fatalf("Unexpected dry run: %+v, %v", run, err)
+	}
+	if _, err := backend.RetrieveSecret(dest, "A", 0); err == nil {
+		t.Error("A dry run must not write")
+	}
+	if len(engine.Statuses()[0].Runs) != 0 {
+		t.Error("Dry runs must not be recorded in the run history")
+	}
+
+	if _, err := engine.RunNow("dr", false); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := backend.RetrieveSecret(dest, "B", 0); v.SecretValue != "local" {
+		t.Error("The skip policy must keep differing destination values")
+	}
+	if ran := engine.RunDue(); len(ran) != 0 {
+		t.Errorf("On-demand jobs must not be scheduled, got %v", ran)
+	}
+}
+
+func TestReplicateNowREST_DryRunAndRateLimits(t *testing.T) {
+	backend := newFakeBackend()
+	source := secretScope{ProjectID: "p-src", Environment: "prod", SecretPath: "/"}
+	dest := secretScope{ProjectID: "p-dst", Environment: "prod", SecretPath: "/"}
+	backend.set(source, "A", "1")
+
+	savedEngine, savedLimits := replications, rateLimits
+	defer func() { replications, rateLimits = savedEngine, savedLimits }()
+	replications = newTestReplicationEngine(t, map[string]*fakeBackend{"": backend}, nil, ReplicationConfig{
+		Jobs: []ReplicationJob{{
+			Name:        "dr",
+			Source:      ReplicationEndpoint{ProjectID: "p-src", Environment: "prod"},
+			Destination: ReplicationEndpoint{ProjectID: "p-dst", Environment: "prod"},
+		}},
+	})
+	rateLimits = &rateLimiter{
+		identity: newTokenBucketLimiter(100, 100),
+		project:  newTokenBucketLimiter(100, 100),
+		metrics:  newRateLimitMetrics(),
+	}
+
+	registerHandlers()
+	e := echo.New()
+	e.POST("/v1/api/replications/:job/run", replicateNowREST)
+	run := func(query string) int {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/api/replications/dr/run"+query, nil))
+		return rec.Code
+	}
+
+	for _, query := range []string{"?dryRun=1", "?dryRun=TRUE", "?dryRun=True"} {
+		if code := run(query); code != http.StatusOK {
+			t.Fatalf("%s: expected a dry run, got %d", query, code)
+		}
+	}
+	if _, err := backend.RetrieveSecret(dest, "A", 0); err == nil {
+		t.Fatal("A dry run requested as 1, TRUE or True must not write")
+	}
+	if code := run("?dryRun=maybe"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid dryRun, got %d", code)
+	}
+
+	// The destination project is charged as well as the source
+	rateLimits.project = newTokenBucketLimiter(0.001, 1)
+	rateLimits.project.Allow("p-dst")
+	if code := run("?dryRun=true"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the exhausted destination project to be rate limited, got %d", code)
+	}
+}
+
+func TestReplicationConfig_Validation(t *testing.T) {
+	endpoint := ReplicationEndpoint{ProjectID: "p", Environment: "prod"}
+	for name, cfg := range map[string]ReplicationConfig{
+		"same scope":       {Jobs: []ReplicationJob{{Name: "j", Source: endpoint, Destination: endpoint}}},
+		"unknown instance": {Jobs: []ReplicationJob{{Name: "j", Source: endpoint, Destination: ReplicationEndpoint{Instance: "x", ProjectID: "p", Environment: "prod"}}}},
+		"short interval":   {Jobs: []ReplicationJob{{Name: "j", Source: endpoint, Destination: ReplicationEndpoint{ProjectID: "p", Environment: "dr"}, Interval: "10s"}}},
+		"fail policy":      {Jobs: []ReplicationJob{{Name: "j", Source: endpoint, Destination: ReplicationEndpoint{ProjectID: "p", Environment: "dr"}, Overwrite: overwriteFail}}},
+		"no credentials":   {Instances: map[string]InfisicalInstance{"x": {URL: "https://x"}}},
+	} {
+		if err := cfg.validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
@@ -13,18 +13,24 @@ import (
 // REST endpoint request types
 
 type CreateSecretRequest struct {
-	Key         string `json:"key"`
-	Value       string `json:"value"`
-	Environment string `json:"environment,omitempty"`
-	ProjectID   string `json:"projectId,omitempty"`
-	SecretPath  string `json:"secretPath,omitempty"`
+	Key         string           `json:"key"`
+	Value       string           `json:"value"`
+	Generate    *generatorPolicy `json:"generate,omitempty"`
+	ReturnValue bool             `json:"returnValue,omitempty"`
+	ExpiresAt   string           `json:"expiresAt,omitempty"`
+	Environment string           `json:"environment,omitempty"`
+	ProjectID   string           `json:"projectId,omitempty"`
+	SecretPath  string           `json:"secretPath,omitempty"`
 }
 
 type UpdateSecretRequest struct {
-	Value       string `json:"value"`
-	Environment string `json:"environment,omitempty"`
-	ProjectID   string `json:"projectId,omitempty"`
-	SecretPath  string `json:"secretPath,omitempty"`
+	Value       string           `json:"value"`
+	Generate    *generatorPolicy `json:"generate,omitempty"`
+	ReturnValue bool             `json:"returnValue,omitempty"`
+	ExpiresAt   string           `json:"expiresAt,omitempty"`
+	Environment string           `json:"environment,omitempty"`
+	ProjectID   string           `json:"projectId,omitempty"`
+	SecretPath  string           `json:"secretPath,omitempty"`
 }
 
 // registerRESTEndpoints adds REST endpoints that convert to semantic actions
@@ -40,6 +46,84 @@ func registerRESTEndpoints(apiGroup *echo.Group, apiKeyMiddleware echo.Middlewar
 
 	// DELETE /v1/api/secrets/:key - Delete secret
 	apiGroup.DELETE("/secrets/:key", deleteSecretREST, apiKeyMiddleware)
+
+	// GET /v1/api/secrets/:key/versions - List version history (no values by default)
+	apiGroup.GET("/secrets/:key/versions", getSecretVersionsREST, apiKeyMiddleware)
+
+	// GET /v1/api/secrets/:key/versions/:version - Retrieve a specific version
+	apiGroup.GET("/secrets/:key/versions/:version", getSecretVersionREST, apiKeyMiddleware)
+
+	// POST /v1/api/secrets/import - Bulk import from a dotenv, JSON or YAML document
+	apiGroup.POST("/secrets/import", importSecretsREST, apiKeyMiddleware)
+
+	// POST /v1/api/secrets/promote - Promote secrets between environments or paths
+	apiGroup.POST("/secrets/promote", promoteREST, apiKeyMiddleware)
+
+	// POST /v1/api/secrets/compare - Diff two scopes without revealing values
+	apiGroup.POST("/secrets/compare", compareREST, apiKeyMiddleware)
+
+	// POST /v1/api/secrets/copy - Copy secrets to another scope
+	apiGroup.POST("/secrets/copy", copySecretsREST, apiKeyMiddleware)
+
+	// POST /v1/api/secrets/move - Move secrets to another scope
+	apiGroup.POST("/secrets/move", moveSecretsREST, apiKeyMiddleware)
+
+	// POST /v1/api/secrets/rollback - Roll back every secret in a path
+	apiGroup.POST("/secrets/rollback", rollbackPathREST, apiKeyMiddleware)
+
+	// POST /v1/api/secrets/:key/rollback - Roll back a single secret
+	apiGroup.POST("/secrets/:key/rollback", rollbackSecretREST, apiKeyMiddleware)
+
+	// GET /v1/api/folders - List folders below a path
+	apiGroup.GET("/folders", listFoldersREST, apiKeyMiddleware)
+
+	// POST /v1/api/folders - Create a folder
+	apiGroup.POST("/folders", createFolderREST, apiKeyMiddleware)
+
+	// PATCH /v1/api/folders/:name - Rename a folder
+	apiGroup.PATCH("/folders/:name", renameFolderREST, apiKeyMiddleware)
+
+	// DELETE /v1/api/folders/:name - Delete a folder (force for non-empty folders)
+	apiGroup.DELETE("/folders/:name", deleteFolderREST, apiKeyMiddleware)
+
+	// GET /v1/api/secret-imports - List the imports of a path
+	apiGroup.GET("/secret-imports", listSecretImportsREST, apiKeyMiddleware)
+
+	// POST /v1/api/secret-imports - Import another environment/path into a path
+	apiGroup.POST("/secret-imports", createSecretImportREST, apiKeyMiddleware)
+
+	// DELETE /v1/api/secret-imports/:id - Remove an import
+	apiGroup.DELETE("/secret-imports/:id", deleteSecretImportREST, apiKeyMiddleware)
+
+	// POST /v1/api/render - Render a configuration file template with secrets
+	apiGroup.POST("/render", renderREST, apiKeyMiddleware)
+
+	// POST /v1/api/resolve - Substitute ${secrets.X} placeholders in a JSON-LD document
+	apiGroup.POST("/resolve", resolveREST, apiKeyMiddleware)
+
+	// GET /v1/api/projects - Projects and environments the identity can access
+	apiGroup.GET("/projects", listProjectsREST, apiKeyMiddleware)
+
+	// GET /v1/api/projects/:projectId - One project with optional folder trees
+	apiGroup.GET("/projects/:projectId", getProjectREST, apiKeyMiddleware)
+
+	// GET /v1/api/targets/validate - Check that a project, environment and path exist
+	apiGroup.GET("/targets/validate", validateTargetREST, apiKeyMiddleware)
+
+	// GET /v1/api/expiring-secrets - Secrets expiring within a window across configured scopes
+	apiGroup.GET("/expiring-secrets", getExpiringSecretsREST, apiKeyMiddleware)
+
+	// GET /v1/api/rotations - Rotation policy status
+	apiGroup.GET("/rotations", getRotationsREST, apiKeyMiddleware)
+
+	// POST /v1/api/rotations/:policy/rotate - Rotate now
+	apiGroup.POST("/rotations/:policy/rotate", rotateNowREST, apiKeyMiddleware)
+
+	// GET /v1/api/replications - Replication job status and recent runs
+	apiGroup.GET("/replications", getReplicationsREST, apiKeyMiddleware)
+
+	// POST /v1/api/replications/:job/run - Replicate now
+	apiGroup.POST("/replications/:job/run", replicateNowREST, apiKeyMiddleware)
 }
 
 // createSecretREST handles REST POST /v1/api/secrets
@@ -52,8 +136,8 @@ func createSecretREST(c echo.Context) error {
 	if req.Key == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "key is required"})
 	}
-	if req.Value == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "value is required"})
+	if req.Value == "" && req.Generate == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "value or generate is required"})
 	}
 
 	// Convert to JSON-LD CreateAction
@@ -66,24 +150,17 @@ func createSecretREST(c echo.Context) error {
 			"value":      req.Value,
 		},
 	}
-
-	// Add target with Infisical configuration
-	target := map[string]interface{}{
-		"@type": "EntryPoint",
-	}
-	if req.ProjectID != "" {
-		target["actionPlatform"] = req.ProjectID
+	if req.Generate != nil {
+		action["generate"] = req.Generate
+		action["returnValue"] = req.ReturnValue
 	}
-	if req.Environment != "" {
-		target["actionApplication"] = req.Environment
-	}
-	if req.SecretPath != "" {
-		target["urlTemplate"] = req.SecretPath
-	}
-	if len(target) > 1 { // More than just @type
-		action["target"] = target
+	if req.ExpiresAt != "" {
+		action["expiresAt"] = req.ExpiresAt
 	}
 
+	// Add target with Infisical configuration
+	addRESTTarget(action, req.ProjectID, req.Environment, req.SecretPath)
+
 	return callSemanticHandler(c, action)
 }
 
@@ -106,21 +183,7 @@ func getSecretREST(c echo.Context) error {
 	}
 
 	// Add target with Infisical configuration
-	target := map[string]interface{}{
-		"@type": "EntryPoint",
-	}
-	if projectID != "" {
-		target["actionPlatform"] = projectID
-	}
-	if environment != "" {
-		target["actionApplication"] = environment
-	}
-	if secretPath != "" {
-		target["urlTemplate"] = secretPath
-	}
-	if len(target) > 1 { // More than just @type
-		action["target"] = target
-	}
+	addRESTTarget(action, projectID, environment, secretPath)
 
 	return callSemanticHandler(c, action)
 }
@@ -137,8 +200,8 @@ func updateSecretREST(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
 	}
 
-	if req.Value == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "value is required"})
+	if req.Value == "" && req.Generate == nil && req.ExpiresAt == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "value, generate or expiresAt is required"})
 	}
 
 	// Convert to JSON-LD UpdateAction
@@ -151,24 +214,17 @@ func updateSecretREST(c echo.Context) error {
 			"value":      req.Value,
 		},
 	}
-
-	// Add target with Infisical configuration
-	target := map[string]interface{}{
-		"@type": "EntryPoint",
-	}
-	if req.ProjectID != "" {
-		target["actionPlatform"] = req.ProjectID
+	if req.Generate != nil {
+		action["generate"] = req.Generate
+		action["returnValue"] = req.ReturnValue
 	}
-	if req.Environment != "" {
-		target["actionApplication"] = req.Environment
-	}
-	if req.SecretPath != "" {
-		target["urlTemplate"] = req.SecretPath
-	}
-	if len(target) > 1 { // More than just @type
-		action["target"] = target
+	if req.ExpiresAt != "" {
+		action["expiresAt"] = req.ExpiresAt
 	}
 
+	// Add target with Infisical configuration
+	addRESTTarget(action, req.ProjectID, req.Environment, req.SecretPath)
+
 	return callSemanticHandler(c, action)
 }
 
@@ -194,6 +250,13 @@ func deleteSecretREST(c echo.Context) error {
 	}
 
 	// Add target with Infisical configuration
+	addRESTTarget(action, projectID, environment, secretPath)
+
+	return callSemanticHandler(c, action)
+}
+
+// addRESTTarget adds an EntryPoint target with the Infisical configuration given in a REST request
+func addRESTTarget(action map[string]interface{}, projectID, environment, secretPath string) {
 	target := map[string]interface{}{
 		"@type": "EntryPoint",
 	}
@@ -209,8 +272,6 @@ func deleteSecretREST(c echo.Context) error {
 	if len(target) > 1 { // More than just @type
 		action["target"] = target
 	}
-
-	return callSemanticHandler(c, action)
 }
 
 // callSemanticHandler converts action to JSON and calls the semantic action handler
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// Rollback outcomes per key
+const (
+	rollbackChanged   = "changed"
+	rollbackUnchanged = "unchanged"
+	rollbackSkipped   = "skipped"
+	rollbackFailed    = "failed"
+)
+
+// rollbackOptions restores secrets to a prior version number or timestamp
+type rollbackOptions struct {
+	// Version restores this version number of the secret(s)
+	Version int `json:"version,omitempty"`
+	// AsOf restores the value that was current at this RFC 3339 timestamp
+	AsOf string `json:"asOf,omitempty"`
+	// DryRun reports which keys would change without writing anything
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// selector validates the options and converts them to a point-in-time selector
+func (o *rollbackOptions) selector() (pointInTimeSelector, error) {
+	switch {
+	case o.Version != 0 && o.AsOf != "":
+		return pointInTimeSelector{}, fmt.Errorf("rollback.version and rollback.asOf are mutually exclusive")
+	case o.Version > 0:
+		return pointInTimeSelector{Version: o.Version}, nil
+	case o.AsOf != "":
+		asOf, err := time.Parse(time.RFC3339, o.AsOf)
+		if err != nil {
+			return pointInTimeSelector{}, fmt.Errorf("rollback.asOf must be an RFC 3339 timestamp: %w", err)
+		}
+		return pointInTimeSelector{AsOf: asOf}, nil
+	}
+	return pointInTimeSelector{}, fmt.Errorf("rollback requires a positive version or an asOf timestamp")
+}
+
+// rollbackEntry reports what a rollback did (or would do) to one key. It never contains values.
+type rollbackEntry struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	FromVersion int    `json:"fromVersion,omitempty"`
+	ToVersion   int    `json:"toVersion,omitempty"`
+	NewVersion  int    `json:"newVersion,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// rollbackSecrets restores the given keys (or every key in the scope when keys
+// is empty) to the selected version
+func rollbackSecrets(backend secretBackend, scope secretScope, keys []string, sel pointInTimeSelector, dryRun bool) ([]rollbackEntry, error) {
+	if len(keys) == 0 {
+		secrets, err := backend.ListSecrets(secretScope{ProjectID: scope.ProjectID, Environment: scope.Environment, SecretPath: scope.SecretPath})
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range secrets {
+			keys = append(keys, secret.SecretKey)
+		}
+	}
+
+	entries := make([]rollbackEntry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, rollbackSecret(backend, scope, key, sel, dryRun))
+	}
+	return entries, nil
+}
+
+// rollbackSecret restores a single key
+func rollbackSecret(backend secretBackend, scope secretScope, key string, sel pointInTimeSelector, dryRun bool) rollbackEntry {
+	entry := rollbackEntry{Name: key}
+
+	current, err := backend.RetrieveSecret(scope, key, 0)
+	if err != nil {
+		entry.Status, entry.Reason = rollbackFailed, err.Error()
+		return entry
+	}
+	entry.FromVersion = current.Version
+	if sel.Version > current.Version {
+		entry.Status, entry.Reason = rollbackSkipped, fmt.Sprintf("version %d does not exist", sel.Version)
+		return entry
+	}
+
+	version, err := resolveVersion(backend, scope, key, sel)
+	if err != nil {
+		// Keys created after the rollback point are left alone rather than deleted
+		entry.Status, entry.Reason = rollbackSkipped, err.Error()
+		return entry
+	}
+	entry.ToVersion = version
+
+	target, err := backend.RetrieveSecret(scope, key, version)
+	if err != nil {
+		entry.Status, entry.Reason = rollbackFailed, err.Error()
+		return entry
+	}
+	if target.SecretValue == current.SecretValue {
+		entry.Status = rollbackUnchanged
+		return entry
+	}
+	// Earlier versions may predate the current validation policies
+	if rejection := serviceConfig.Validation.rejection(scope, key, target.SecretValue); rejection != "" {
+		entry.Status, entry.Reason = rollbackFailed, rejection
+		return entry
+	}
+
+	entry.Status = rollbackChanged
+	if dryRun {
+		return entry
+	}
+	updated, err := backend.UpdateSecret(scope, key, target.SecretValue)
+	if err != nil {
+		entry.Status, entry.Reason = rollbackFailed, err.Error()
+		return entry
+	}
+	entry.NewVersion = updated.Version
+	return entry
+}
+
+// rollbackResult builds the action result describing a rollback
+func rollbackResult(entries []rollbackEntry, dryRun bool) *semantic.SemanticResult {
+	summary := map[string]int{}
+	for _, e := range entries {
+		summary[e.Status]++
+	}
+	return &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"dryRun":  dryRun,
+			"summary": summary,
+			"entries": entries,
+		},
+	}
+}
+
+// RollbackRequest is the body of the REST rollback endpoints
+type RollbackRequest struct {
+	Version     int    `json:"version,omitempty"`
+	AsOf        string `json:"asOf,omitempty"`
+	DryRun      bool   `json:"dryRun,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	ProjectID   string `json:"projectId,omitempty"`
+	SecretPath  string `json:"secretPath,omitempty"`
+}
+
+// rollbackSecretREST handles REST POST /v1/api/secrets/:key/rollback
+func rollbackSecretREST(c echo.Context) error {
+	return rollbackREST(c, c.Param("key"))
+}
+
+// rollbackPathREST handles REST POST /v1/api/secrets/rollback (every key in the path)
+func rollbackPathREST(c echo.Context) error {
+	return rollbackREST(c, "")
+}
+
+// rollbackREST converts a REST rollback request to an UpdateAction
+func rollbackREST(c echo.Context, key string) error {
+	var req RollbackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD UpdateAction with rollback options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "UpdateAction",
+		"rollback": rollbackOptions{Version: req.Version, AsOf: req.AsOf, DryRun: req.DryRun},
+	}
+	if key != "" {
+		action["object"] = map[string]interface{}{
+			"@type":      "PropertyValue",
+			"identifier": key,
+		}
+	}
+	addRESTTarget(action, req.ProjectID, req.Environment, req.SecretPath)
+
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRollbackSecrets_DryRunAndApply(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/db"}
+	backend := newFakeBackend()
+	backend.set(scope, "DB_PASSWORD", "good")    // v1 at 01:00
+	backend.set(scope, "DB_HOST", "db.internal") // v1 at 02:00
+	checkpoint := backend.now
+	backend.set(scope, "DB_PASSWORD", "broken") // v2 at 03:00
+	backend.set(scope, "DB_USER", "new-user")   // v1 at 04:00, created after the checkpoint
+
+	sel := pointInTimeSelector{AsOf: checkpoint}
+
+	preview, err := rollbackSecrets(backend, scope, nil, sel, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	want := map[string]string{
+		"DB_HOST":     rollbackUnchanged,
+		"DB_PASSWORD": rollbackChanged,
+		"DB_USER":     rollbackSkipped,
+	}
+	for _, e := range preview {
+		if e.Status != want[e.Name] {
+			t.Errorf("Dry run: %s expected %s, got %s (%s)", e.Name, want[e.Name], e.Status, e.Reason)
+		}
+	}
+	if current, _ := backend.RetrieveSecret(scope, "DB_PASSWORD", 0); current.SecretValue != "broken" {
+		t.Fatal("Dry run must not modify secrets")
+	}
+
+	applied, err := rollbackSecrets(backend, scope, []string{"DB_PASSWORD"}, sel, false)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if len(applied) != 1 || applied[0].FromVersion != 2 || applied[0].ToVersion != 1 || applied[0].NewVersion != 3 {
+		t.Errorf("Unexpected rollback entry: %+v", applied)
+	}
+	if current, _ := backend.RetrieveSecret(scope, "DB_PASSWORD", 0); current.SecretValue != "good" {
+		t.Errorf("Expected DB_PASSWORD restored to 'good', got %q", current.SecretValue)
+	}
+}
+
+func TestRollbackSecret_RejectedByValidation(t *testing.T) {
+	saved := serviceConfig
+	defer func() { serviceConfig = saved }()
+	serviceConfig = &ServiceConfig{Validation: *testValidationConfig(t)}
+
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "API_URL", "https://example.com") // forbidden by the urls policy
+	backend.set(scope, "API_URL", "https://api.internal")
+
+	for _, dryRun := range []bool{true, false} {
+		entry := rollbackSecret(backend, scope, "API_URL", pointInTimeSelector{Version: 1}, dryRun)
+		if entry.Status != rollbackFailed || !strings.HasPrefix(entry.Reason, "rejected by validation: ") {
+			t.Errorf("dryRun=%v: expected the rejected version to fail, got %+v", dryRun, entry)
+		}
+	}
+	if current, _ := backend.RetrieveSecret(scope, "API_URL", 0); current.SecretValue != "https://api.internal" {
+		t.Errorf("Expected API_URL unchanged, got %q", current.SecretValue)
+	}
+}
+
+func TestRollbackSecret_UnknownVersionSkipped(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "KEY", "only")
+
+	entry := rollbackSecret(backend, scope, "KEY", pointInTimeSelector{Version: 5}, false)
+	if entry.Status != rollbackSkipped {
+		t.Errorf("Expected skipped for missing version, got %+v", entry)
+	}
+}
+
+func TestRollbackOptions_Selector(t *testing.T) {
+	if _, err := (&rollbackOptions{}).selector(); err == nil {
+		t.Error("Expected error without version or asOf")
+	}
+	if _, err := (&rollbackOptions{Version: 1, AsOf: time.Now().Format(time.RFC3339)}).selector(); err == nil {
+		t.Error("Expected error when combining version and asOf")
+	}
+	if sel, err := (&rollbackOptions{Version: 2}).selector(); err != nil || sel.Version != 2 {
+		t.Errorf("Unexpected selector %+v, %v", sel, err)
+	}
+}
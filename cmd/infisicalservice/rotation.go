@@ -0,0 +1,529 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// Rotation run outcomes
+const (
+	rotationSucceeded = "succeeded"
+	rotationFailed    = "failed"
+)
+
+// Rotation triggers
+const (
+	rotationTriggerSchedule = "schedule"
+	rotationTriggerManual   = "manual"
+)
+
+const (
+	defaultRotationCheckInterval = time.Minute
+	minRotationInterval          = time.Minute
+	defaultHookTimeout           = 10 * time.Second
+	// rotationRetryDelay keeps the scheduler from retrying a failed policy on every check
+	rotationRetryDelay = 15 * time.Minute
+)
+
+// RotationConfig configures scheduled secret rotation
+type RotationConfig struct {
+	// CheckInterval is how often the scheduler looks for due policies (Go duration, default 1m)
+	CheckInterval string           `json:"checkInterval,omitempty"`
+	Policies      []RotationPolicy `json:"policies,omitempty"`
+
+	checkInterval time.Duration
+}
+
+// RotationPolicy rotates one secret key on an interval with a generator
+type RotationPolicy struct {
+	Name        string `json:"name"`
+	ProjectID   string `json:"projectId"`
+	Environment string `json:"environment"`
+	SecretPath  string `json:"secretPath,omitempty"`
+	Key         string `json:"key"`
+	// Interval is the rotation period (Go duration, e.g. "720h")
+	Interval string          `json:"interval"`
+	Generate generatorPolicy `json:"generate"`
+	// PreHooks run before the new value is written; a failing pre-hook aborts the rotation
+	PreHooks []RotationHook `json:"preHooks,omitempty"`
+	// PostHooks run after the new value is written, e.g. to make consumers reload it
+	PostHooks []RotationHook `json:"postHooks,omitempty"`
+
+	interval time.Duration
+}
+
+// RotationHook is an HTTP call to another service. It receives the policy,
+// scope, key and version, never the secret value.
+type RotationHook struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Timeout is the request timeout (Go duration, default 10s)
+	Timeout string `json:"timeout,omitempty"`
+
+	timeout time.Duration
+}
+
+// validate checks the rotation configuration and applies defaults
+func (r *RotationConfig) validate() error {
+	r.checkInterval = defaultRotationCheckInterval
+	if r.CheckInterval != "" {
+		d, err := time.ParseDuration(r.CheckInterval)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("rotation.checkInterval %q is not a positive duration", r.CheckInterval)
+		}
+		r.checkInterval = d
+	}
+
+	names := map[string]bool{}
+	for i := range r.Policies {
+		p := &r.Policies[i]
+		if p.Name == "" {
+			return fmt.Errorf("rotation.policies[%d]: name is required", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("rotation policy %q is defined twice", p.Name)
+		}
+		names[p.Name] = true
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("rotation policy %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *RotationPolicy) validate() error {
+	if p.ProjectID == "" || p.Environment == "" || p.Key == "" {
+		return fmt.Errorf("projectId, environment and key are required")
+	}
+	if p.SecretPath == "" {
+		p.SecretPath = "/"
+	}
+	d, err := time.ParseDuration(p.Interval)
+	if err != nil || d < minRotationInterval {
+		return fmt.Errorf("interval %q must be a duration of at least %s", p.Interval, minRotationInterval)
+	}
+	p.interval = d
+	if err := p.Generate.validate(); err != nil {
+		return err
+	}
+	for _, hooks := range [][]RotationHook{p.PreHooks, p.PostHooks} {
+		for i := range hooks {
+			if err := hooks[i].validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *RotationHook) validate() error {
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("hook url %q must be an absolute http(s) URL", h.URL)
+	}
+	if h.Method == "" {
+		h.Method = http.MethodPost
+	}
+	h.timeout = defaultHookTimeout
+	if h.Timeout != "" {
+		d, err := time.ParseDuration(h.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("hook timeout %q is not a positive duration", h.Timeout)
+		}
+		h.timeout = d
+	}
+	return nil
+}
+
+// scope returns the secret scope the policy applies to
+func (p *RotationPolicy) scope() secretScope {
+	return secretScope{ProjectID: p.ProjectID, Environment: p.Environment, SecretPath: p.SecretPath}
+}
+
+// rotationStatus is the last known state of a policy. It never contains values.
+type rotationStatus struct {
+	Policy      string    `json:"policy"`
+	Key         string    `json:"key"`
+	Scope       string    `json:"scope"`
+	Interval    string    `json:"interval"`
+	LastRotated time.Time `json:"lastRotated,omitzero"`
+	NextDue     time.Time `json:"nextDue,omitzero"`
+	LastRun     time.Time `json:"lastRun,omitzero"`
+	LastStatus  string    `json:"lastStatus,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+	Version     int       `json:"version,omitempty"`
+	Running     bool      `json:"running"`
+}
+
+// rotationEngine runs rotation policies on schedule and on demand
+type rotationEngine struct {
+	policies map[string]*RotationPolicy
+	store    operationStore
+	connect  func() (secretBackend, error)
+	client   *http.Client
+	now      func() time.Time
+
+	mu     sync.Mutex
+	status map[string]*rotationStatus
+}
+
+// rotations is the engine configured from the service configuration file
+var rotations = newRotationEngine(nil, nil)
+
+// newRotationEngine creates an engine for the given policies. Operations are
+// recorded in store when it is not nil.
+func newRotationEngine(policies []RotationPolicy, store operationStore) *rotationEngine {
+	e := &rotationEngine{
+		policies: make(map[string]*RotationPolicy),
+		store:    store,
+		connect: func() (secretBackend, error) {
+			conn, err := infisicalConnectionFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			return connectBackend(conn)
+		},
+		client: &http.Client{},
+		now:    time.Now,
+		status: make(map[string]*rotationStatus),
+	}
+	for i := range policies {
+		p := &policies[i]
+		e.policies[p.Name] = p
+		e.status[p.Name] = &rotationStatus{Policy: p.Name, Key: p.Key, Scope: p.scope().String(), Interval: p.Interval}
+	}
+	return e
+}
+
+// Statuses returns the state of every policy, sorted by name
+func (e *rotationEngine) Statuses() []rotationStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statuses := make([]rotationStatus, 0, len(e.status))
+	for _, s := range e.status {
+		statuses = append(statuses, *s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Policy < statuses[j].Policy })
+	return statuses
+}
+
+// Run checks for due policies at the given interval until stop is closed
+func (e *rotationEngine) Run(interval time.Duration, stop <-chan struct{}) {
+	if len(e.policies) == 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultRotationCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.RotateDue()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// RotateDue rotates every policy whose interval has elapsed and returns the names rotated
+func (e *rotationEngine) RotateDue() []string {
+	names := make([]string, 0, len(e.policies))
+	for name := range e.policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	backend, err := e.connect()
+	if err != nil {
+		log.Printf("Rotation scheduler: %v", err)
+		return nil
+	}
+
+	var rotated []string
+	for _, name := range names {
+		due, err := e.due(backend, e.policies[name])
+		if err != nil {
+			log.Printf("Rotation scheduler: policy %s: %v", name, err)
+			continue
+		}
+		if !due {
+			continue
+		}
+		if _, err := e.rotate(backend, name, rotationTriggerSchedule); err == nil {
+			rotated = append(rotated, name)
+		}
+	}
+	return rotated
+}
+
+// due reports whether a policy should be rotated now. The last rotation time is
+// taken from the secret's version history, so schedules survive restarts.
+func (e *rotationEngine) due(backend secretBackend, p *RotationPolicy) (bool, error) {
+	e.mu.Lock()
+	status := e.status[p.Name]
+	lastRotated := status.LastRotated
+	retryAt := status.LastRun.Add(rotationRetryDelay)
+	failed := status.LastStatus == rotationFailed
+	e.mu.Unlock()
+
+	if failed && e.now().Before(retryAt) {
+		return false, nil
+	}
+
+	if lastRotated.IsZero() {
+		secret, err := backend.RetrieveSecret(p.scope(), p.Key, 0)
+		if errors.Is(err, errSecretNotFound) {
+			// A missing key is created by its first rotation
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		versions, err := backend.ListSecretVersions(secret)
+		if err != nil {
+			return false, err
+		}
+		if len(versions) == 0 {
+			return true, nil
+		}
+		lastRotated = versions[len(versions)-1].CreatedAt
+
+		e.mu.Lock()
+		status.LastRotated = lastRotated
+		status.NextDue = lastRotated.Add(p.interval)
+		status.Version = secret.Version
+		e.mu.Unlock()
+	}
+	return !e.now().Before(lastRotated.Add(p.interval)), nil
+}
+
+// RotateNow rotates a policy immediately, regardless of its schedule
+func (e *rotationEngine) RotateNow(name string) (rotationStatus, error) {
+	if _, ok := e.policies[name]; !ok {
+		return rotationStatus{}, fmt.Errorf("unknown rotation policy %q", name)
+	}
+	backend, err := e.connect()
+	if err != nil {
+		return rotationStatus{}, err
+	}
+	return e.rotate(backend, name, rotationTriggerManual)
+}
+
+// rotate runs the pre-hooks, writes a newly generated value and runs the post-hooks
+func (e *rotationEngine) rotate(backend secretBackend, name, trigger string) (rotationStatus, error) {
+	p := e.policies[name]
+
+	e.mu.Lock()
+	status := e.status[name]
+	if status.Running {
+		e.mu.Unlock()
+		return *status, fmt.Errorf("rotation of %q is already running", name)
+	}
+	status.Running = true
+	e.mu.Unlock()
+
+	operationID := newOperationID()
+	if e.store != nil {
+		e.store.StartOperation(operationID, "SecretRotation", map[string]interface{}{
+			"policy":  name,
+			"key":     redaction.KeyName(p.Key),
+			"trigger": trigger,
+		})
+	}
+
+	start := e.now()
+	version, err := e.rotateSecret(backend, p, operationID)
+
+	e.mu.Lock()
+	status.Running = false
+	status.LastRun = start
+	if version > 0 {
+		// The value was replaced even if a post-hook failed afterwards
+		status.LastRotated = start
+		status.NextDue = start.Add(p.interval)
+		status.Version = version
+	}
+	if err != nil {
+		status.LastStatus, status.LastError = rotationFailed, err.Error()
+	} else {
+		status.LastStatus, status.LastError = rotationSucceeded, ""
+	}
+	result := *status
+	e.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Rotation of policy %s failed: %v", name, err)
+		if e.store != nil {
+			e.store.FailOperation(operationID, err)
+		}
+		return result, err
+	}
+
+	log.Printf("Rotated %s (%s) by policy %s, version %d", redaction.KeyName(p.Key), p.scope(), name, version)
+	auditEvent("secret.rotated", map[string]interface{}{
+		"policy":      name,
+		"projectId":   p.ProjectID,
+		"environment": p.Environment,
+		"secretPath":  p.SecretPath,
+		"key":         redaction.KeyName(p.Key),
+		"version":     version,
+		"trigger":     trigger,
+	})
+	if e.store != nil {
+		e.store.CompleteOperation(operationID, map[string]interface{}{
+			"policy":     name,
+			"key":        redaction.KeyName(p.Key),
+			"version":    version,
+			"trigger":    trigger,
+			"durationMs": e.now().Sub(start).Milliseconds(),
+		})
+	}
+	return result, nil
+}
+
+// rotateSecret performs one rotation and returns the new version of the key
+func (e *rotationEngine) rotateSecret(backend secretBackend, p *RotationPolicy, operationID string) (int, error) {
+	scope := p.scope()
+	event := map[string]interface{}{
+		"rotationId":  operationID,
+		"policy":      p.Name,
+		"projectId":   p.ProjectID,
+		"environment": p.Environment,
+		"secretPath":  p.SecretPath,
+		"key":         p.Key,
+	}
+
+	event["phase"] = "pre"
+	for _, hook := range p.PreHooks {
+		if err := e.callHook(hook, event); err != nil {
+			return 0, fmt.Errorf("pre-hook failed, secret not rotated: %w", err)
+		}
+	}
+
+	_, err := backend.RetrieveSecret(scope, p.Key, 0)
+	if err != nil && !errors.Is(err, errSecretNotFound) {
+		return 0, fmt.Errorf("failed to read current secret: %w", err)
+	}
+	create := err != nil
+	policy := p.Generate
+	generated, err := policy.generate(p.Key)
+	if err != nil {
+		return 0, err
+	}
+	versions, err := storeGenerated(backend, scope, generated, create)
+	if err != nil {
+		return 0, err
+	}
+
+	event["phase"] = "post"
+	event["version"] = versions[0]
+	for _, hook := range p.PostHooks {
+		if err := e.callHook(hook, event); err != nil {
+			return versions[0], fmt.Errorf("secret rotated to version %d but post-hook failed: %w", versions[0], err)
+		}
+	}
+	return versions[0], nil
+}
+
+// callHook sends the rotation event to a hook and requires a 2xx response
+func (e *rotationEngine) callHook(hook RotationHook, event map[string]interface{}) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(hook.Method, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	timeout := hook.timeout
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+	client := *e.client
+	client.Timeout = timeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", hook.Method, redactURL(hook.URL), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: HTTP %d", hook.Method, redactURL(hook.URL), resp.StatusCode)
+	}
+	return nil
+}
+
+// redactURL drops the query string and credentials from a hook URL for error messages
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid url>"
+	}
+	u.User, u.RawQuery, u.Fragment = nil, "", ""
+	return strings.TrimSuffix(u.String(), "?")
+}
+
+// rotateOptions is the UpdateAction property requesting an immediate rotation
+type rotateOptions struct {
+	Policy string `json:"policy"`
+}
+
+// handleRotateNow rotates a configured policy on demand
+func handleRotateNow(c echo.Context, action *semantic.SemanticAction, opts *rotateOptions) error {
+	p, ok := rotations.policies[opts.Policy]
+	if opts.Policy == "" || !ok {
+		return semantic.ReturnActionError(c, action, "Invalid rotate options", fmt.Errorf("unknown rotation policy %q", opts.Policy))
+	}
+	if limited, err := rateLimits.CheckProject(c, p.ProjectID); limited {
+		return err
+	}
+
+	status, err := rotations.RotateNow(opts.Policy)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Rotation failed", err)
+	}
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value:  status,
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// getRotationsREST handles REST GET /v1/api/rotations
+func getRotationsREST(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{"policies": rotations.Statuses()})
+}
+
+// rotateNowREST handles REST POST /v1/api/rotations/:policy/rotate
+func rotateNowREST(c echo.Context) error {
+	// Convert to JSON-LD UpdateAction with rotate options
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "UpdateAction",
+		"rotate":   rotateOptions{Policy: c.Param("policy")},
+	}
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRotationEngine creates an engine over a fake backend with a controllable clock
+func newTestRotationEngine(t *testing.T, backend *fakeBackend, store operationStore, policies ...RotationPolicy) *rotationEngine {
+	t.Helper()
+	cfg := RotationConfig{Policies: policies}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Invalid rotation config: %v", err)
+	}
+	e := newRotationEngine(cfg.Policies, store)
+	e.connect = func() (secretBackend, error) { return backend, nil }
+	e.now = func() time.Time { return backend.now }
+	return e
+}
+
+func TestRotationEngine_ScheduleAndHooks(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/s3"}
+	backend := newFakeBackend()
+	backend.set(scope, "S3_SECRET_KEY", "initial") // v1 at 01:00
+
+	var events []map[string]interface{}
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event)
+	}))
+	defer hook.Close()
+
+	store := &recordingStore{}
+	engine := newTestRotationEngine(t, backend, store, RotationPolicy{
+		Name: "s3", ProjectID: "p", Environment: "prod", SecretPath: "/s3", Key: "S3_SECRET_KEY",
+		Interval:  "24h",
+		Generate:  generatorPolicy{Type: generatorToken},
+		PreHooks:  []RotationHook{{URL: hook.URL + "/pre"}},
+		PostHooks: []RotationHook{{URL: hook.URL + "/post"}},
+	})
+
+	if rotated := engine.RotateDue(); len(rotated) != 0 {
+		t.Fatalf("Policy rotated before its interval elapsed: %v", rotated)
+	}
+
+	backend.now = backend.now.Add(25 * time.Hour)
+	rotatedAt := backend.now
+	if rotated := engine.RotateDue(); len(rotated) != 1 {
+		t.Fatalf("Expected the due policy to rotate, got %v", rotated)
+	}
+	current, _ := backend.RetrieveSecret(scope, "S3_SECRET_KEY", 0)
+	if current.Version != 2 || current.SecretValue == "initial" {
+		t.Errorf("Expected a new generated version, got v%d", current.Version)
+	}
+
+	if len(events) != 2 || events[0]["phase"] != "pre" || events[1]["phase"] != "post" || events[1]["version"] != float64(2) {
+		t.Errorf("Unexpected hook events: %v", events)
+	}
+	for _, event := range events {
+		data, _ := json.Marshal(event)
+		if strings.Contains(string(data), current.SecretValue) {
+			t.Error("Hooks must not receive the secret value")
+		}
+	}
+	if len(store.started) != 1 || store.started[0] != "SecretRotation" || len(store.completed) != 1 {
+		t.Errorf("Expected one completed SecretRotation operation, got %v / %v", store.started, store.completed)
+	}
+
+	if rotated := engine.RotateDue(); len(rotated) != 0 {
+		t.Errorf("Policy rotated again immediately: %v", rotated)
+	}
+	status := engine.Statuses()[0]
+	if status.LastStatus != rotationSucceeded || status.Version != 2 || !status.NextDue.Equal(rotatedAt.Add(24*time.Hour)) {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+func TestRotationEngine_PreHookFailureAborts(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "DB_PASSWORD", "initial")
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer hook.Close()
+
+	store := &recordingStore{}
+	engine := newTestRotationEngine(t, backend, store, RotationPolicy{
+		Name: "db", ProjectID: "p", Environment: "prod", Key: "DB_PASSWORD",
+		Interval: "1h",
+		Generate: generatorPolicy{Type: generatorPassword},
+		PreHooks: []RotationHook{{URL: hook.URL}},
+	})
+
+	status, err := engine.RotateNow("db")
+	if err == nil || status.LastStatus != rotationFailed {
+		t.Fatalf("Expected failed rotation, got %+v, %v", status, err)
+	}
+	if current, _ := backend.RetrieveSecret(scope, "DB_PASSWORD", 0); current.Version != 1 {
+		t.Error("A failing pre-hook must leave the secret unchanged")
+	}
+	if len(store.failed) != 1 {
+		t.Errorf("Expected a failed operation, got %v", store.failed)
+	}
+
+	// The scheduler waits before retrying a failed policy
+	backend.now = backend.now.Add(2 * time.Hour)
+	engine.status["db"].LastRun = backend.now
+	if rotated := engine.RotateDue(); len(rotated) != 0 {
+		t.Errorf("Failed policy retried before the retry delay: %v", rotated)
+	}
+}
+
+func TestRotationEngine_CreatesMissingKey(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	backend := newFakeBackend()
+	engine := newTestRotationEngine(t, backend, nil, RotationPolicy{
+		Name: "api", ProjectID: "p", Environment: "dev", Key: "API_TOKEN",
+		Interval: "720h",
+		Generate: generatorPolicy{Type: generatorUUID},
+	})
+
+	if rotated := engine.RotateDue(); len(rotated) != 1 {
+		t.Fatalf("Expected missing key to be created, got %v", rotated)
+	}
+	if _, err := backend.RetrieveSecret(scope, "API_TOKEN", 0); err != nil {
+		t.Errorf("Expected API_TOKEN to exist: %v", err)
+	}
+	if _, err := engine.RotateNow("unknown"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestRotationEngine_ReadFailureDoesNotCreate(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "API_TOKEN", "initial")
+	backend.failReads = true
+	engine := newTestRotationEngine(t, backend, nil, RotationPolicy{
+		Name: "api", ProjectID: "p", Environment: "dev", Key: "API_TOKEN",
+		Interval: "720h",
+		Generate: generatorPolicy{Type: generatorUUID},
+	})
+
+	if _, err := engine.RotateNow("api"); err == nil || !strings.Contains(err.Error(), "read of API_TOKEN failed") {
+		t.Fatalf("Expected the read failure to be reported, got %v", err)
+	}
+	backend.failReads = false
+	if current, _ := backend.RetrieveSecret(scope, "API_TOKEN", 0); current.Version != 1 {
+		t.Errorf("Expected API_TOKEN unchanged, got version %d", current.Version)
+	}
+}
+
+func TestLoadServiceConfig_RotationPolicies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"rotation":{"policies":[{"name":"db","projectId":"p","environment":"prod","key":"DB_PASSWORD","interval":"720h","generate":{"type":"password"},"postHooks":[{"url":"http://dbservice:8080/v1/api/reload"}]}]}}`)
+	cfg, err := loadServiceConfig(path)
+	if err != nil {
+		t.Fatalf("Valid rotation config rejected: %v", err)
+	}
+	p := cfg.Rotation.Policies[0]
+	if p.SecretPath != "/" || p.interval != 720*time.Hour || p.PostHooks[0].Method != http.MethodPost {
+		t.Errorf("Defaults not applied: %+v", p)
+	}
+
+	write(`{"rotation":{"policies":[{"name":"db","projectId":"p","environment":"prod","key":"K","interval":"10s","generate":{"type":"password"}}]}}`)
+	if _, err := loadServiceConfig(path); err == nil {
+		t.Error("Expected error for an interval below the minimum")
+	}
+}
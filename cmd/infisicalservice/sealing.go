@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"eve.evalgo.org/semantic"
+	"infisicalservice/seal"
+)
+
+// Encryption modes for sealed results
+const (
+	// sealModeValue seals every secret value individually, leaving key names readable
+	sealModeValue = "value"
+	// sealModeDataset seals the whole result set as one envelope
+	sealModeDataset = "dataset"
+)
+
+// sealedFormat is the encoding format of a result sealed as a whole
+const sealedFormat = "application/vnd.infisicalservice.sealed+json"
+
+// encryptionOptions asks for results sealed to the caller's public key
+type encryptionOptions struct {
+	// RecipientPublicKey is a PEM PKIX key (RSA or X25519) or a base64 raw X25519 key
+	RecipientPublicKey string `json:"recipientPublicKey"`
+	// Mode is "value" (default) or "dataset"
+	Mode string `json:"mode,omitempty"`
+}
+
+// recipientKey validates the options and parses the recipient public key
+func (o *encryptionOptions) recipientKey() (interface{}, error) {
+	if o.RecipientPublicKey == "" {
+		return nil, fmt.Errorf("encryption.recipientPublicKey is required")
+	}
+	switch o.Mode {
+	case "":
+		o.Mode = sealModeValue
+	case sealModeValue, sealModeDataset:
+	default:
+		return nil, fmt.Errorf("unsupported encryption mode %q (use %q or %q)", o.Mode, sealModeValue, sealModeDataset)
+	}
+	return seal.ParsePublicKey(o.RecipientPublicKey)
+}
+
+// sealResult builds a result whose secret values are sealed to the recipient key
+func sealResult(secrets []interface{}, mode string, recipient interface{}) (*semantic.SemanticResult, error) {
+	if mode == sealModeDataset {
+		plaintext, err := json.Marshal(secrets)
+		if err != nil {
+			return nil, err
+		}
+		envelope, err := seal.Seal(recipient, plaintext)
+		if err != nil {
+			return nil, err
+		}
+		return &semantic.SemanticResult{
+			Type:   "Dataset",
+			Format: sealedFormat,
+			Value:  envelope,
+		}, nil
+	}
+
+	sealed := make([]interface{}, 0, len(secrets))
+	for _, entry := range secrets {
+		fields, err := entryFields(entry)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := fields["value"].(string); ok {
+			envelope, err := seal.SealString(recipient, value)
+			if err != nil {
+				return nil, err
+			}
+			delete(fields, "value")
+			fields["sealedValue"] = envelope
+		}
+		sealed = append(sealed, fields)
+	}
+
+	return &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value:  sealed,
+		Schema: &semantic.ResultSchema{
+			Type: "PropertyValueList",
+			Properties: []semantic.PropertyValueSpec{
+				{Type: "PropertyValue", Name: "name", ValueType: "Text", Description: "Secret key name"},
+				{Type: "PropertyValue", Name: "sealedValue", ValueType: "StructuredValue", Description: "Secret value sealed to the recipient public key"},
+			},
+		},
+	}, nil
+}
+
+// entryFields copies a result entry into a generic map
+func entryFields(entry interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	switch e := entry.(type) {
+	case map[string]string:
+		for k, v := range e {
+			fields[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range e {
+			fields[k] = v
+		}
+	default:
+		return nil, fmt.Errorf("unexpected secret entry type %T", entry)
+	}
+	return fields, nil
+}
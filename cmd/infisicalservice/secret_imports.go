@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+
+	"eve.evalgo.org/semantic"
+	"github.com/infisical/go-sdk/packages/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Origins of retrieved secrets
+const (
+	originLocal  = "local"
+	originImport = "import"
+)
+
+// secretOrigin tells where a secret served by a folder comes from
+type secretOrigin struct {
+	Origin      string
+	Environment string
+	SecretPath  string
+}
+
+// mergeImportedSecrets merges the secrets of a folder with those of its
+// imports. Like the SDK, the folder's own secrets take precedence, followed
+// by the imports in order.
+func mergeImportedSecrets(local []models.Secret, imports []models.SecretImport) ([]models.Secret, map[string]secretOrigin) {
+	merged := append([]models.Secret(nil), local...)
+	origins := make(map[string]secretOrigin, len(local))
+	for _, s := range local {
+		origins[s.SecretKey] = secretOrigin{Origin: originLocal}
+	}
+	for _, imp := range imports {
+		for _, s := range imp.Secrets {
+			if _, ok := origins[s.SecretKey]; ok {
+				continue
+			}
+			origins[s.SecretKey] = secretOrigin{Origin: originImport, Environment: imp.Environment, SecretPath: imp.SecretPath}
+			merged = append(merged, s)
+		}
+	}
+	return merged, origins
+}
+
+// addSecretOrigin adds origin and, for imported secrets, importEnvironment and importPath to a retrieved entry
+func addSecretOrigin(entry map[string]string, origin secretOrigin) {
+	entry["origin"] = origin.Origin
+	if origin.Origin == originImport {
+		entry["importEnvironment"] = origin.Environment
+		entry["importPath"] = origin.SecretPath
+	}
+}
+
+// secretImportOptions selects a secret import on the target path. Imports are
+// created from environment and secretPath and deleted by id or by both.
+type secretImportOptions struct {
+	ID          string `json:"id,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	SecretPath  string `json:"secretPath,omitempty"`
+}
+
+// validate checks the options and applies defaults
+func (o *secretImportOptions) validate(create bool) error {
+	if o.SecretPath != "" {
+		o.SecretPath = path.Clean("/" + o.SecretPath)
+	}
+	if o.ID != "" && !create {
+		return nil
+	}
+	if o.Environment == "" {
+		if create {
+			return fmt.Errorf("secretImport.environment is required")
+		}
+		return fmt.Errorf("secretImport.id or secretImport.environment is required")
+	}
+	if o.SecretPath == "" {
+		o.SecretPath = "/"
+	}
+	return nil
+}
+
+// matches reports whether an existing import is the one selected
+func (o *secretImportOptions) matches(imp secretImport) bool {
+	if o.ID != "" {
+		return imp.ID == o.ID
+	}
+	return imp.Environment == o.Environment && imp.SecretPath == o.SecretPath
+}
+
+// secretImportEntry is one import of a listing with the number of secrets it provides
+type secretImportEntry struct {
+	secretImport
+	Secrets int `json:"secrets"`
+}
+
+// listSecretImports lists the imports of a folder with the number of secrets each provides
+func listSecretImports(backend secretBackend, scope secretScope) ([]secretImportEntry, error) {
+	imports, err := backend.ListSecretImports(scope)
+	if err != nil {
+		return nil, err
+	}
+	_, blocks, err := backend.ListSecretsBySource(scope)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]secretImportEntry, len(imports))
+	for i, imp := range imports {
+		entries[i] = secretImportEntry{secretImport: imp}
+		for _, block := range blocks {
+			if block.Environment == imp.Environment && path.Clean(block.SecretPath) == path.Clean(imp.SecretPath) {
+				entries[i].Secrets = len(block.Secrets)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// handleListSecretImports lists the imports configured on the target path
+func handleListSecretImports(c echo.Context, action *semantic.SemanticAction) error {
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	entries, err := listSecretImports(backend, scope)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to list secret imports", err)
+	}
+	log.Printf("Listed %d secret imports (%s)", len(entries), scope)
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"path":    scope.SecretPath,
+			"imports": append([]secretImportEntry{}, entries...),
+		},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// handleCreateSecretImport imports the secrets of another environment and path into the target path
+func handleCreateSecretImport(c echo.Context, action *semantic.SemanticAction, opts *secretImportOptions) error {
+	if err := opts.validate(true); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid secretImport options", err)
+	}
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	if opts.Environment == scope.Environment && opts.SecretPath == scope.SecretPath {
+		return semantic.ReturnActionError(c, action, "Invalid secretImport options", fmt.Errorf("a folder cannot import itself"))
+	}
+
+	existing, err := backend.ListSecretImports(scope)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to list secret imports", err)
+	}
+	for _, imp := range existing {
+		if opts.matches(imp) {
+			return semantic.ReturnActionError(c, action, "Invalid secretImport options", fmt.Errorf("%s:%s is already imported", opts.Environment, opts.SecretPath))
+		}
+	}
+
+	created, err := backend.CreateSecretImport(scope, secretImport{Environment: opts.Environment, SecretPath: opts.SecretPath})
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to create secret import", err)
+	}
+	log.Printf("Imported %s:%s into %s", opts.Environment, opts.SecretPath, scope)
+	auditEvent("secretImport.created", map[string]interface{}{
+		"projectId":         scope.ProjectID,
+		"environment":       scope.Environment,
+		"secretPath":        scope.SecretPath,
+		"importEnvironment": created.Environment,
+		"importPath":        created.SecretPath,
+	})
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value:  created,
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// handleDeleteSecretImport removes an import from the target path
+func handleDeleteSecretImport(c echo.Context, action *semantic.SemanticAction, opts *secretImportOptions) error {
+	if err := opts.validate(false); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid secretImport options", err)
+	}
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+
+	existing, err := backend.ListSecretImports(scope)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to list secret imports", err)
+	}
+	var target *secretImport
+	for i := range existing {
+		if opts.matches(existing[i]) {
+			target = &existing[i]
+			break
+		}
+	}
+	if target == nil {
+		return semantic.ReturnActionError(c, action, "Failed to delete secret import", fmt.Errorf("no matching secret import on %s", scope.SecretPath))
+	}
+	if err := backend.DeleteSecretImport(scope, target.ID); err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to delete secret import", err)
+	}
+	log.Printf("Removed import of %s:%s from %s", target.Environment, target.SecretPath, scope)
+	auditEvent("secretImport.deleted", map[string]interface{}{
+		"projectId":         scope.ProjectID,
+		"environment":       scope.Environment,
+		"secretPath":        scope.SecretPath,
+		"importEnvironment": target.Environment,
+		"importPath":        target.SecretPath,
+	})
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value:  map[string]interface{}{"id": target.ID, "environment": target.Environment, "secretPath": target.SecretPath, "deleted": true},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// SecretImportRequest is the body of the REST secret import endpoint
+type SecretImportRequest struct {
+	ProjectID   string `json:"projectId,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	// Path is the importing folder (default /)
+	Path              string `json:"path,omitempty"`
+	SourceEnvironment string `json:"sourceEnvironment"`
+	SourcePath        string `json:"sourcePath,omitempty"`
+}
+
+// listSecretImportsREST handles REST GET /v1/api/secret-imports
+func listSecretImportsREST(c echo.Context) error {
+	// Convert to JSON-LD RetrieveAction with secretImports
+	action := map[string]interface{}{
+		"@context":      "https://schema.org",
+		"@type":         "RetrieveAction",
+		"secretImports": true,
+	}
+	addRESTTarget(action, c.QueryParam("projectId"), c.QueryParam("environment"), c.QueryParam("path"))
+
+	return callSemanticHandler(c, action)
+}
+
+// createSecretImportREST handles REST POST /v1/api/secret-imports
+func createSecretImportREST(c echo.Context) error {
+	var req SecretImportRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD CreateAction with secretImport options
+	action := map[string]interface{}{
+		"@context":     "https://schema.org",
+		"@type":        "CreateAction",
+		"secretImport": secretImportOptions{Environment: req.SourceEnvironment, SecretPath: req.SourcePath},
+	}
+	addRESTTarget(action, req.ProjectID, req.Environment, req.Path)
+
+	return callSemanticHandler(c, action)
+}
+
+// deleteSecretImportREST handles REST DELETE /v1/api/secret-imports/:id
+func deleteSecretImportREST(c echo.Context) error {
+	// Convert to JSON-LD DeleteAction with secretImport options
+	action := map[string]interface{}{
+		"@context":     "https://schema.org",
+		"@type":        "DeleteAction",
+		"secretImport": secretImportOptions{ID: c.Param("id")},
+	}
+	addRESTTarget(action, c.QueryParam("projectId"), c.QueryParam("environment"), c.QueryParam("path"))
+
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestSecretImports_OriginsAndManagement(t *testing.T) {
+	app := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/app", IncludeImports: true}
+	shared := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/shared"}
+	global := secretScope{ProjectID: "p", Environment: "global", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(app, "APP_PORT", "8080")
+	backend.set(app, "LOG_LEVEL", "info")
+	backend.set(shared, "LOG_LEVEL", "debug")
+	backend.set(shared, "S3_BUCKET", "px-semantic")
+	backend.set(global, "S3_BUCKET", "other")
+	backend.set(global, "SMTP_HOST", "mail")
+
+	backend.CreateSecretImport(app, secretImport{Environment: "prod", SecretPath: "/shared"})
+	backend.CreateSecretImport(app, secretImport{Environment: "global", SecretPath: "/"})
+
+	entries, err := fetchSecrets(backend, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]map[string]string{}
+	for _, e := range entries {
+		entry := e.(map[string]string)
+		got[entry["name"]] = entry
+	}
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 merged secrets, got %v", got)
+	}
+	if got["LOG_LEVEL"]["origin"] != originLocal || got["LOG_LEVEL"]["value"] != "info" {
+		t.Errorf("Local secrets must take precedence, got %v", got["LOG_LEVEL"])
+	}
+	if got["S3_BUCKET"]["importPath"] != "/shared" || got["S3_BUCKET"]["value"] != "px-semantic" {
+		t.Errorf("The first import must win, got %v", got["S3_BUCKET"])
+	}
+	if got["SMTP_HOST"]["origin"] != originImport || got["SMTP_HOST"]["importEnvironment"] != "global" {
+		t.Errorf("Expected SMTP_HOST from global:/, got %v", got["SMTP_HOST"])
+	}
+
+	withoutImports := app
+	withoutImports.IncludeImports = false
+	plain, _ := fetchSecrets(backend, withoutImports)
+	if _, ok := plain[0].(map[string]string)["origin"]; ok || len(plain) != 2 {
+		t.Errorf("Origins are only reported when imports are included, got %v", plain)
+	}
+
+	listed, err := listSecretImports(backend, app)
+	if err != nil || len(listed) != 2 || listed[0].Secrets != 2 || listed[1].Environment != "global" {
+		t.Fatalf("Unexpected import listing: %+v, %v", listed, err)
+	}
+}
+
+func TestSecretImportOptions(t *testing.T) {
+	opts := &secretImportOptions{Environment: "dev", SecretPath: "shared/"}
+	if err := opts.validate(true); err != nil || opts.SecretPath != "/shared" {
+		t.Errorf("Expected cleaned path, got %q, %v", opts.SecretPath, err)
+	}
+	if !opts.matches(secretImport{ID: "x", Environment: "dev", SecretPath: "/shared"}) {
+		t.Error("Expected match by environment and path")
+	}
+	if err := (&secretImportOptions{}).validate(false); err == nil {
+		t.Error("Expected error without id or environment")
+	}
+	if err := (&secretImportOptions{ID: "import-1"}).validate(true); err == nil {
+		t.Error("Expected error creating an import without environment")
+	}
+}
@@ -1,16 +1,16 @@
 package main
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os"
-	"strings"
+	"text/template"
+	"time"
 
 	"eve.evalgo.org/semantic"
-	infisical "github.com/infisical/go-sdk"
+	"github.com/infisical/go-sdk/packages/models"
 	"github.com/labstack/echo/v4"
 )
 
@@ -24,7 +24,9 @@ func handleSemanticAction(c echo.Context) error {
 		})
 	}
 
-	// Parse as SemanticAction
+	// Parse as SemanticAction; a target given as an alias name is passed on as
+	// an object, the form the parser accepts
+	body = normalizeAliasTarget(body)
 	action, err := semantic.ParseSemanticAction(body)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -32,117 +34,284 @@ func handleSemanticAction(c echo.Context) error {
 		})
 	}
 
+	// Keep the raw body so handlers can read service-specific action properties
+	c.Set(rawActionContextKey, body)
+
 	// Dispatch to registered handler using the ActionRegistry
 	// No switch statement needed - handlers are registered at startup
 	return semantic.Handle(c, action)
 }
 
+// rawActionContextKey is the echo context key holding the raw JSON-LD action body
+const rawActionContextKey = "infisicalservice.rawAction"
+
+// decodeActionProperties decodes service-specific properties of the current
+// action (those not modelled by semantic.SemanticAction) into v
+func decodeActionProperties(c echo.Context, v interface{}) error {
+	body, ok := c.Get(rawActionContextKey).([]byte)
+	if !ok || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid action properties: %w", err)
+	}
+	return nil
+}
+
+// actionObject is the PropertyValue object of a secret action
+type actionObject struct {
+	Identifier string `json:"identifier"`
+	Value      string `json:"value,omitempty"`
+}
+
+// retrieveOptions holds the optional RetrieveAction properties
+type retrieveOptions struct {
+	// Object selects a single secret key; required for history and point-in-time retrieval
+	Object *actionObject `json:"object,omitempty"`
+	// History lists the version history of Object instead of returning values
+	History bool `json:"history,omitempty"`
+	// IncludeValues adds values to the version history (omitted by default)
+	IncludeValues bool `json:"includeValues,omitempty"`
+	// Version retrieves a specific version of Object
+	Version int `json:"version,omitempty"`
+	// AsOf retrieves the version of Object that was current at this RFC 3339 timestamp
+	AsOf string `json:"asOf,omitempty"`
+
+	// Expiring returns the expiring-secrets report for the configured scopes instead of values
+	Expiring *expiringOptions `json:"expiring,omitempty"`
+	// Compare returns a diff report of two scopes instead of values
+	Compare *compareOptions `json:"compare,omitempty"`
+	// Folders lists the folders below the target path instead of values
+	Folders *foldersOptions `json:"folders,omitempty"`
+	// Composite derives values from the retrieved secrets with templates
+	Composite *compositeOptions `json:"composite,omitempty"`
+	// Render returns a configuration file rendered with secrets instead of values
+	Render *renderOptions `json:"render,omitempty"`
+	// Resolve returns a JSON-LD document with its ${secrets.X} placeholders substituted
+	Resolve *resolveOptions `json:"resolve,omitempty"`
+	// SecretImports lists the imports configured on the target path instead of values
+	SecretImports bool `json:"secretImports,omitempty"`
+	// Discover lists the accessible projects and environments; it needs no target
+	Discover *discoverOptions `json:"discover,omitempty"`
+	// ValidateTarget reports whether the target project, environment and path exist
+	ValidateTarget bool `json:"validateTarget,omitempty"`
+
+	Encryption *encryptionOptions `json:"encryption,omitempty"`
+	Wrap       *wrapOptions       `json:"wrap,omitempty"`
+}
+
 // handleRetrieveAction handles Infisical secret retrieval actions
 func handleRetrieveAction(c echo.Context, actionInterface interface{}) error {
 	action, ok := actionInterface.(*semantic.SemanticAction)
 	if !ok {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid action type")
 	}
-	// Extract Infisical target configuration using helper
-	_, projectID, environment, secretPath, includeImports, err := semantic.GetInfisicalTargetFromAction(action)
+	var opts retrieveOptions
+	if err := decodeActionProperties(c, &opts); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid RetrieveAction", err)
+	}
+	if opts.Expiring != nil {
+		return handleExpiringReport(c, action, opts.Expiring)
+	}
+	if opts.Compare != nil {
+		return handleCompare(c, action, opts.Compare)
+	}
+	if opts.Folders != nil {
+		return handleListFolders(c, action, opts.Folders)
+	}
+	if opts.SecretImports {
+		return handleListSecretImports(c, action)
+	}
+	if opts.Discover != nil {
+		return handleDiscover(c, action, opts.Discover)
+	}
+	if opts.ValidateTarget {
+		return handleValidateTarget(c, action)
+	}
+	if opts.Render != nil {
+		return handleRender(c, action, opts.Render, opts.Encryption, opts.Wrap)
+	}
+	if opts.Resolve != nil {
+		return handleResolve(c, action, opts.Resolve, opts.Encryption, opts.Wrap)
+	}
+	pointInTime, err := opts.pointInTime()
 	if err != nil {
-		return semantic.ReturnActionError(c, action, "Failed to extract Infisical target", err)
+		return semantic.ReturnActionError(c, action, "Invalid RetrieveAction", err)
 	}
 
-	// Get Infisical server URL from environment
-	infisicalURL := os.Getenv("INFISICAL_API_URL")
-	if infisicalURL == "" {
-		infisicalURL = "https://app.infisical.com" // Default to Infisical Cloud
+	// Parse composite templates before touching Infisical so mistakes fail fast
+	var templates map[string]*template.Template
+	if opts.Composite != nil {
+		if opts.History || pointInTime != nil {
+			return semantic.ReturnActionError(c, action, "Invalid composite options", fmt.Errorf("composite cannot be combined with history, version or asOf"))
+		}
+		if templates, err = opts.Composite.parse(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid composite options", err)
+		}
 	}
 
-	// Get Infisical credentials from environment
-	clientID := os.Getenv("INFISICAL_CLIENT_ID")
-	clientSecret := os.Getenv("INFISICAL_CLIENT_SECRET")
+	// Parse the recipient key before touching Infisical so bad keys fail fast
+	var recipient interface{}
+	if opts.Encryption != nil {
+		if recipient, err = opts.Encryption.recipientKey(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid encryption options", err)
+		}
+	}
+
+	if opts.Wrap != nil {
+		if _, _, err := opts.Wrap.parse(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid wrap options", err)
+		}
+	}
 
-	if clientID == "" || clientSecret == "" {
-		return semantic.ReturnActionError(c, action, "Infisical credentials not configured", nil)
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
 	}
 
 	// Execute secret retrieval using the extracted configuration
-	log.Printf("Retrieving secrets from Infisical (url=%s, project=%s, env=%s, path=%s, includeImports=%v)", infisicalURL, projectID, environment, secretPath, includeImports)
+	log.Printf("Retrieving secrets from Infisical (%s, includeImports=%v)", scope, scope.IncludeImports)
 
-	// Use EVE's Infisical integration to fetch secrets
-	secrets, err := fetchSecretsFromInfisical(infisicalURL, clientID, clientSecret, projectID, environment, secretPath, includeImports)
-	if err != nil {
-		return semantic.ReturnActionError(c, action, "Failed to retrieve secrets from Infisical", err)
-	}
-
-	log.Printf("Successfully retrieved %d secrets", len(secrets))
-
-	// Store result using semantic Result structure
-	// This follows Schema.org Dataset pattern with credentials as PropertyValues
-	action.Result = &semantic.SemanticResult{
-		Type:   "Dataset",
-		Format: "application/json",
-		Value:  secrets, // Structured data as array of {name, value} maps
-		Schema: &semantic.ResultSchema{
-			Type: "PropertyValueList",
-			Properties: []semantic.PropertyValueSpec{
-				{Type: "PropertyValue", Name: "name", ValueType: "Text", Description: "Secret key name"},
-				{Type: "PropertyValue", Name: "value", ValueType: "Text", Description: "Secret value"},
+	var secrets []interface{}
+	switch {
+	case opts.History:
+		versions, err := secretVersionHistory(backend, scope, opts.Object.Identifier, opts.IncludeValues)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to list secret versions", err)
+		}
+		log.Printf("Retrieved %d versions of %s", len(versions), redaction.KeyName(opts.Object.Identifier))
+		action.Result = versionHistoryResult(versions, opts.IncludeValues)
+		if opts.IncludeValues {
+			secrets = versions
+		}
+
+	case pointInTime != nil:
+		secret, err := secretAtPointInTime(backend, scope, opts.Object.Identifier, *pointInTime)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to retrieve secret version", err)
+		}
+		log.Printf("Retrieved version %v of %s", secret["version"], redaction.KeyName(opts.Object.Identifier))
+		secrets = []interface{}{secret}
+		action.Result = secretVersionResult(secrets)
+
+	default:
+		secrets, err = fetchSecrets(backend, scope)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to retrieve secrets from Infisical", err)
+		}
+		log.Printf("Successfully retrieved %d secrets", len(secrets))
+
+		// Render derived values over the retrieved secrets
+		if templates != nil {
+			rendered, err := renderComposites(templates, secrets)
+			if err != nil {
+				return semantic.ReturnActionError(c, action, "Failed to render composite templates", err)
+			}
+			if secrets, err = withComposites(secrets, rendered, opts.Composite.Only); err != nil {
+				return semantic.ReturnActionError(c, action, "Failed to render composite templates", err)
+			}
+			log.Printf("Rendered %d composite values (only=%v)", len(rendered), opts.Composite.Only)
+		}
+
+		// Store result using semantic Result structure
+		// This follows Schema.org Dataset pattern with credentials as PropertyValues
+		action.Result = &semantic.SemanticResult{
+			Type:   "Dataset",
+			Format: "application/json",
+			Value:  secrets, // Structured data as array of {name, value} maps
+			Schema: &semantic.ResultSchema{
+				Type: "PropertyValueList",
+				Properties: []semantic.PropertyValueSpec{
+					{Type: "PropertyValue", Name: "name", ValueType: "Text", Description: "Secret key name"},
+					{Type: "PropertyValue", Name: "value", ValueType: "Text", Description: "Secret value"},
+				},
 			},
-		},
+		}
+	}
+
+	// Seal values to the caller's public key if requested
+	if opts.Encryption != nil && secrets != nil {
+		sealed, err := sealResult(secrets, opts.Encryption.Mode, recipient)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to encrypt secrets", err)
+		}
+		action.Result = sealed
+	}
+
+	// Hand out a single-use token instead of the secrets if requested
+	if opts.Wrap != nil {
+		wrapped, err := wrapResult(action.Result, opts.Wrap)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to wrap secrets", err)
+		}
+		action.Result = wrapped
 	}
 
 	semantic.SetSuccessOnAction(action)
 	return c.JSON(http.StatusOK, action)
 }
 
-// maskSecretValue masks a secret value for logging
-func maskSecretValue(value string) string {
-	if len(value) <= 8 {
-		return "***"
+// actionBackend extracts the Infisical target of an action, applies per-project
+// rate limiting and connects to Infisical. When done is true a response has
+// already been written and err is what the handler should return.
+func actionBackend(c echo.Context, action *semantic.SemanticAction) (secretBackend, secretScope, bool, error) {
+	// Extract the Infisical target, resolving aliases
+	scope, identity, err := actionTarget(c, action)
+	if err != nil {
+		return nil, secretScope{}, true, semantic.ReturnActionError(c, action, "Failed to extract Infisical target", err)
 	}
-	return value[:2] + "..." + value[len(value)-2:]
-}
-
-// fetchSecretsFromInfisical retrieves secrets from Infisical using the Go SDK
-func fetchSecretsFromInfisical(url, clientID, clientSecret, projectID, environment, secretPath string, includeImports bool) ([]interface{}, error) {
-	// Extract host from URL (remove https:// prefix)
-	host := strings.TrimPrefix(url, "https://")
-	host = strings.TrimPrefix(host, "http://")
 
-	// Create Infisical client
-	client := infisical.NewInfisicalClient(context.Background(), infisical.Config{
-		SiteUrl:          "https://" + host,
-		AutoTokenRefresh: false,
-	})
+	// Per-project rate limiting protects the Infisical quota from runaway workflows
+	if limited, err := rateLimits.CheckProject(c, scope.ProjectID); limited {
+		return nil, scope, true, err
+	}
 
-	// Authenticate
-	_, err := client.Auth().UniversalAuthLogin(clientID, clientSecret)
+	// Get Infisical connection settings from the environment or the alias identity
+	conn, err := instanceConnection(serviceConfig.Replication.Instances, identity)
 	if err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", err)
+		return nil, scope, true, semantic.ReturnActionError(c, action, "Infisical credentials not configured", err)
 	}
 
-	log.Printf("DEBUG: Fetching secrets with SDK - projectID=%s, env=%s, path=%s, includeImports=%v", projectID, environment, secretPath, includeImports)
-
-	// Fetch secrets
-	apiKeySecrets, err := client.Secrets().List(infisical.ListSecretsOptions{
-		AttachToProcessEnv: false,
-		Environment:        environment,
-		ProjectID:          projectID,
-		SecretPath:         secretPath,
-		IncludeImports:     includeImports,
-	})
+	backend, err := connectBackend(conn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list secrets: %w", err)
+		return nil, scope, true, semantic.ReturnActionError(c, action, "Failed to connect to Infisical", err)
 	}
+	return backend, scope, false, nil
+}
 
-	log.Printf("DEBUG: SDK returned %d secrets", len(apiKeySecrets))
+// fetchSecrets retrieves all secrets of a scope as {name, value} entries
+func fetchSecrets(backend secretBackend, scope secretScope) ([]interface{}, error) {
+	// Fetch secrets; with imports, remember where each secret comes from
+	var apiKeySecrets []models.Secret
+	var origins map[string]secretOrigin
+	if scope.IncludeImports {
+		local, imports, err := backend.ListSecretsBySource(scope)
+		if err != nil {
+			return nil, err
+		}
+		apiKeySecrets, origins = mergeImportedSecrets(local, imports)
+	} else {
+		var err error
+		if apiKeySecrets, err = backend.ListSecrets(scope); err != nil {
+			return nil, err
+		}
+	}
 
-	// Convert to semantic.PropertyValue format
+	// Convert to semantic.PropertyValue format; secrets near or past their
+	// expiry date also carry expiresAt and expiryWarning
+	now := time.Now()
 	secrets := make([]interface{}, len(apiKeySecrets))
 	for idx, secret := range apiKeySecrets {
-		secrets[idx] = map[string]string{
+		entry := map[string]string{
 			"name":  secret.SecretKey,
 			"value": secret.SecretValue,
 		}
-		log.Printf("Retrieved secret: %s = %s", secret.SecretKey, maskSecretValue(secret.SecretValue))
+		addExpiryWarning(entry, secret, now)
+		if origins != nil {
+			addSecretOrigin(entry, origins[secret.SecretKey])
+		}
+		secrets[idx] = entry
+		log.Printf("Retrieved secret: %s", redaction.KeyName(secret.SecretKey))
 	}
 
 	return secrets, nil
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"eve.evalgo.org/statemanager"
+	"github.com/labstack/echo/v4"
+)
+
+// operationStore receives operation lifecycle events.
+// Everything passed to it must already be redacted.
+type operationStore interface {
+	StartOperation(id, operationType string, metadata map[string]interface{})
+	CompleteOperation(id string, result interface{})
+	FailOperation(id string, err error)
+}
+
+// stateManagerStore adapts the EVE state manager to operationStore
+type stateManagerStore struct {
+	sm *statemanager.Manager
+}
+
+func (s stateManagerStore) StartOperation(id, operationType string, metadata map[string]interface{}) {
+	s.sm.StartOperation(id, operationType, metadata)
+}
+
+func (s stateManagerStore) CompleteOperation(id string, result interface{}) {
+	s.sm.CompleteOperation(id, result)
+}
+
+func (s stateManagerStore) FailOperation(id string, err error) {
+	s.sm.FailOperation(id, err)
+}
+
+// actionHandler is the signature of handlers registered with the semantic action registry
+type actionHandler = func(c echo.Context, actionInterface interface{}) error
+
+// operationTracker records every semantic action execution (start, success,
+// failure, duration) without ever storing secret values
+type operationTracker struct {
+	store operationStore
+}
+
+// newOperationTracker creates a tracker writing to the given store
+func newOperationTracker(store operationStore) *operationTracker {
+	return &operationTracker{store: store}
+}
+
+// Track wraps an action handler with operation tracking
+func (t *operationTracker) Track(handler actionHandler) actionHandler {
+	return func(c echo.Context, actionInterface interface{}) error {
+		action, ok := actionInterface.(*semantic.SemanticAction)
+		if !ok {
+			return handler(c, actionInterface)
+		}
+
+		operationID := newOperationID()
+		t.store.StartOperation(operationID, action.Type, map[string]interface{}{
+			"actionType": action.Type,
+			"identifier": action.Identifier,
+		})
+
+		start := time.Now()
+		err := handler(c, action)
+		duration := time.Since(start)
+
+		status := c.Response().Status
+		if he, ok := err.(*echo.HTTPError); ok {
+			status = he.Code
+		}
+		if err != nil || status >= http.StatusBadRequest {
+			t.store.FailOperation(operationID, fmt.Errorf("%s failed with HTTP status %d after %s", action.Type, status, duration))
+			return err
+		}
+
+		t.store.CompleteOperation(operationID, map[string]interface{}{
+			"actionType": action.Type,
+			"identifier": action.Identifier,
+			"durationMs": duration.Milliseconds(),
+			"result":     redactResult(action.Result),
+		})
+		return nil
+	}
+}
+
+// newOperationID generates a unique identifier for a tracked operation
+func newOperationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("op-%d", time.Now().UnixNano())
+	}
+	return "op-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// recordingStore captures operation events for assertions
+type recordingStore struct {
+	started   []string
+	completed []interface{}
+	failed    []error
+}
+
+func (r *recordingStore) StartOperation(id, operationType string, metadata map[string]interface{}) {
+	r.started = append(r.started, operationType)
+}
+
+func (r *recordingStore) CompleteOperation(id string, result interface{}) {
+	r.completed = append(r.completed, result)
+}
+
+func (r *recordingStore) FailOperation(id string, err error) {
+	r.failed = append(r.failed, err)
+}
+
+func TestOperationTracker_StoresOnlyRedactedResults(t *testing.T) {
+	store := &recordingStore{}
+	tracker := newOperationTracker(store)
+
+	handler := tracker.Track(func(c echo.Context, actionInterface interface{}) error {
+		action := actionInterface.(*semantic.SemanticAction)
+		action.Result = &semantic.SemanticResult{
+			Type:   "Dataset",
+			Format: "application/json",
+			Value: []interface{}{
+				map[string]string{"name": "BASEX_PASSWORD", "value": "s3cr3t-value"},
+			},
+		}
+		return c.JSON(http.StatusOK, action)
+	})
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/v1/api/semantic/action", nil), rec)
+
+	if err := handler(c, &semantic.SemanticAction{Type: "RetrieveAction"}); err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	if len(store.started) != 1 || len(store.completed) != 1 || len(store.failed) != 0 {
+		t.Fatalf("Expected one start and one completion, got %d/%d/%d", len(store.started), len(store.completed), len(store.failed))
+	}
+
+	stored, _ := json.Marshal(store.completed[0])
+	if strings.Contains(string(stored), "s3cr3t-value") {
+		t.Errorf("Stored operation result contains a secret value: %s", stored)
+	}
+	if !strings.Contains(rec.Body.String(), "s3cr3t-value") {
+		t.Error("HTTP response should still contain the secret value for the caller")
+	}
+}
+
+func TestOperationTracker_RecordsFailure(t *testing.T) {
+	store := &recordingStore{}
+	tracker := newOperationTracker(store)
+
+	handler := tracker.Track(func(c echo.Context, actionInterface interface{}) error {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "boom"})
+	})
+
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/v1/api/semantic/action", nil), httptest.NewRecorder())
+	_ = handler(c, &semantic.SemanticAction{Type: "RetrieveAction"})
+
+	if len(store.failed) != 1 || len(store.completed) != 0 {
+		t.Errorf("Expected one failure and no completion, got %d/%d", len(store.failed), len(store.completed))
+	}
+}
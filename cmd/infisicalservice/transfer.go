@@ -0,0 +1,382 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"eve.evalgo.org/semantic"
+	"github.com/infisical/go-sdk/packages/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Transfer modes
+const (
+	transferCopy = "copy"
+	transferMove = "move"
+)
+
+// Transfer outcomes per key
+const (
+	transferCopied     = "copied"
+	transferMoved      = "moved"
+	transferSkipped    = "skipped"
+	transferFailed     = "failed"
+	transferRolledBack = "rolledBack"
+)
+
+// transferOptions is the TransferAction property set. The action target is the
+// source scope; toLocation is the destination.
+type transferOptions struct {
+	// ToLocation is the destination scope; projectId and environment default to the source
+	ToLocation *secretScope `json:"toLocation"`
+	keyFilter
+	// Mode is copy (default) or move (delete from the source once every key is written)
+	Mode string `json:"mode,omitempty"`
+	// Overwrite is skip (default), overwrite or fail for keys that exist in the destination with a different value
+	Overwrite string `json:"overwrite,omitempty"`
+	DryRun    bool   `json:"dryRun,omitempty"`
+}
+
+// validate checks the options and applies defaults
+func (o *transferOptions) validate() error {
+	if o.ToLocation == nil || o.ToLocation.SecretPath == "" {
+		return fmt.Errorf("toLocation.secretPath is required")
+	}
+	switch o.Mode {
+	case "":
+		o.Mode = transferCopy
+	case transferCopy, transferMove:
+	default:
+		return fmt.Errorf("mode must be copy or move")
+	}
+	switch o.Overwrite {
+	case "":
+		o.Overwrite = overwriteSkip
+	case overwriteSkip, overwriteAlways, overwriteFail:
+	default:
+		return fmt.Errorf("overwrite must be skip, overwrite or fail")
+	}
+	return o.keyFilter.validate()
+}
+
+// destination returns the destination scope for a source scope
+func (o *transferOptions) destination(source secretScope) secretScope {
+	dest := secretScope{ProjectID: o.ToLocation.ProjectID, Environment: o.ToLocation.Environment, SecretPath: o.ToLocation.SecretPath}
+	if dest.ProjectID == "" {
+		dest.ProjectID = source.ProjectID
+	}
+	if dest.Environment == "" {
+		dest.Environment = source.Environment
+	}
+	return dest
+}
+
+// transferEntry reports what a transfer did (or would do) with one key. It never contains values.
+type transferEntry struct {
+	Name string `json:"name"`
+	// Action is create, update, none (the destination already holds the value) or skip
+	Action  string `json:"action"`
+	Status  string `json:"status,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// planTransfer decides the action for every selected source key
+func planTransfer(sourceSecrets, destSecrets []models.Secret, opts *transferOptions) ([]transferEntry, map[string]models.Secret, error) {
+	bySource := make(map[string]models.Secret, len(sourceSecrets))
+	for _, s := range sourceSecrets {
+		bySource[s.SecretKey] = s
+	}
+	for _, key := range opts.Keys {
+		if _, ok := bySource[key]; !ok {
+			return nil, nil, fmt.Errorf("key %s does not exist in the source", key)
+		}
+	}
+
+	var entries []transferEntry
+	for _, k := range compareSecretSets(sourceSecrets, destSecrets) {
+		if !k.InLeft || !opts.matches(k.Name) {
+			continue
+		}
+		entry := transferEntry{Name: k.Name}
+		switch {
+		case !k.InRight:
+			entry.Action = promoteActionCreate
+		case k.Equal:
+			entry.Action = promoteActionNone
+		case opts.Overwrite == overwriteAlways:
+			entry.Action = promoteActionUpdate
+		default:
+			entry.Action, entry.Status = promoteActionSkip, transferSkipped
+			entry.Reason = "destination value differs (overwrite policy: " + opts.Overwrite + ")"
+		}
+		entries = append(entries, entry)
+	}
+	return entries, bySource, nil
+}
+
+// executeTransfer writes the planned keys to the destination and, for moves,
+// deletes them from the source. A move that fails part-way undoes the
+// destination writes of every key still present in the source.
+func executeTransfer(backend secretBackend, source, dest secretScope, mode string, entries []transferEntry, sourceSecrets map[string]models.Secret) error {
+	// previous holds the destination state before each write, for rollback
+	previous := make(map[int]*models.Secret)
+	var failed []string
+	for i := range entries {
+		e := &entries[i]
+		if e.Action == promoteActionSkip || e.Action == promoteActionNone {
+			continue
+		}
+		secret := sourceSecrets[e.Name]
+		if rejection := serviceConfig.Validation.rejection(dest, e.Name, secret.SecretValue); rejection != "" {
+			e.Status, e.Reason = transferFailed, rejection
+			failed = append(failed, e.Name)
+			continue
+		}
+		before := &models.Secret{}
+		if e.Action == promoteActionUpdate {
+			current, err := backend.RetrieveSecret(dest, e.Name, 0)
+			if err != nil {
+				e.Status, e.Reason = transferFailed, err.Error()
+				failed = append(failed, e.Name)
+				continue
+			}
+			before = &current
+		}
+
+		var written models.Secret
+		var err error
+		if e.Action == promoteActionCreate {
+			written, err = backend.CreateSecret(dest, e.Name, secret.SecretValue)
+		} else {
+			written, err = backend.UpdateSecret(dest, e.Name, secret.SecretValue)
+		}
+		if err == nil {
+			previous[i] = before
+			if len(secret.SecretMetadata) > 0 {
+				_, err = backend.UpdateSecretMetadata(dest, e.Name, secret.SecretMetadata)
+			}
+		}
+		if err != nil {
+			e.Status, e.Reason = transferFailed, err.Error()
+			failed = append(failed, e.Name)
+			continue
+		}
+		e.Version = written.Version
+	}
+
+	if mode != transferMove {
+		for i := range entries {
+			if entries[i].Status == "" {
+				entries[i].Status = transferCopied
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%d keys failed: %s", len(failed), strings.Join(failed, ", "))
+		}
+		return nil
+	}
+
+	if len(failed) == 0 {
+		for i := range entries {
+			e := &entries[i]
+			if e.Status != "" {
+				continue
+			}
+			if err := backend.DeleteSecret(source, e.Name); err != nil {
+				e.Status, e.Reason = transferFailed, "deleting from the source failed: "+err.Error()
+				failed = append(failed, e.Name)
+				continue
+			}
+			e.Status = transferMoved
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+	}
+
+	// Undo the destination writes of keys that are still in the source
+	for i := range entries {
+		e := &entries[i]
+		if e.Status == transferMoved || e.Status == transferSkipped {
+			continue
+		}
+		before, wrote := previous[i]
+		if !wrote {
+			if e.Status == "" {
+				e.Status, e.Reason = transferSkipped, "move failed for other keys"
+			}
+			continue
+		}
+		var err error
+		if before.ID == "" {
+			err = backend.DeleteSecret(dest, e.Name)
+		} else if _, err = backend.UpdateSecret(dest, e.Name, before.SecretValue); err == nil && len(sourceSecrets[e.Name].SecretMetadata) > 0 {
+			_, err = backend.UpdateSecretMetadata(dest, e.Name, before.SecretMetadata)
+		}
+		switch {
+		case err != nil:
+			e.Status, e.Reason = transferFailed, "rollback failed: "+err.Error()
+		case e.Status == transferFailed:
+			e.Reason += " (destination write rolled back)"
+		default:
+			e.Status, e.Reason = transferRolledBack, "move failed for other keys"
+		}
+		e.Version = 0
+	}
+	return fmt.Errorf("move failed for %d keys (%s); destination writes were rolled back", len(failed), strings.Join(failed, ", "))
+}
+
+// handleTransferAction copies or moves secrets from the action target to another path, environment or project
+func handleTransferAction(c echo.Context, actionInterface interface{}) error {
+	action, ok := actionInterface.(*semantic.SemanticAction)
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid action type")
+	}
+	var opts transferOptions
+	if err := decodeActionProperties(c, &opts); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid TransferAction", err)
+	}
+	if err := opts.validate(); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid TransferAction", err)
+	}
+
+	backend, source, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	dest := opts.destination(source)
+	if dest == (secretScope{ProjectID: source.ProjectID, Environment: source.Environment, SecretPath: source.SecretPath}) {
+		return semantic.ReturnActionError(c, action, "Invalid TransferAction", fmt.Errorf("source and destination are the same"))
+	}
+	if dest.ProjectID != source.ProjectID {
+		if limited, err := rateLimits.CheckProject(c, dest.ProjectID); limited {
+			return err
+		}
+	}
+
+	sourceSecrets, err := backend.ListSecrets(secretScope{ProjectID: source.ProjectID, Environment: source.Environment, SecretPath: source.SecretPath})
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to list source secrets", err)
+	}
+	destSecrets, err := backend.ListSecrets(dest)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to list destination secrets", err)
+	}
+	entries, bySource, err := planTransfer(sourceSecrets, destSecrets, &opts)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid TransferAction", err)
+	}
+
+	if opts.Overwrite == overwriteFail && !opts.DryRun {
+		var conflicts []string
+		for _, e := range entries {
+			if e.Action == promoteActionSkip {
+				conflicts = append(conflicts, e.Name)
+			}
+		}
+		if len(conflicts) > 0 {
+			return semantic.ReturnActionError(c, action, "Transfer aborted", fmt.Errorf("destination values differ for %d keys: %s", len(conflicts), strings.Join(conflicts, ", ")))
+		}
+	}
+
+	var transferErr error
+	if !opts.DryRun {
+		transferErr = executeTransfer(backend, source, dest, opts.Mode, entries, bySource)
+	}
+
+	summary := map[string]int{}
+	for _, e := range entries {
+		if e.Status != "" {
+			summary[e.Status]++
+		} else {
+			summary[e.Action]++
+		}
+	}
+	log.Printf("Transfer (%s) %s -> %s (dryRun=%v): %v", opts.Mode, source, dest, opts.DryRun, summary)
+	if !opts.DryRun {
+		auditEvent("secret.transfer", map[string]interface{}{
+			"mode":              opts.Mode,
+			"sourceProjectId":   source.ProjectID,
+			"sourceEnvironment": source.Environment,
+			"sourcePath":        source.SecretPath,
+			"destProjectId":     dest.ProjectID,
+			"destEnvironment":   dest.Environment,
+			"destPath":          dest.SecretPath,
+			"summary":           summary,
+			"success":           transferErr == nil,
+		})
+	}
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"mode":        opts.Mode,
+			"dryRun":      opts.DryRun,
+			"source":      source,
+			"destination": dest,
+			"summary":     summary,
+			"entries":     entries,
+		},
+	}
+	if transferErr != nil {
+		return semantic.ReturnActionError(c, action, "Transfer failed", transferErr)
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// TransferRequest is the body of the REST copy and move endpoints
+type TransferRequest struct {
+	ProjectID              string   `json:"projectId,omitempty"`
+	Environment            string   `json:"environment,omitempty"`
+	SecretPath             string   `json:"secretPath,omitempty"`
+	DestinationProjectID   string   `json:"destinationProjectId,omitempty"`
+	DestinationEnvironment string   `json:"destinationEnvironment,omitempty"`
+	DestinationSecretPath  string   `json:"destinationSecretPath"`
+	Keys                   []string `json:"keys,omitempty"`
+	Include                []string `json:"include,omitempty"`
+	Exclude                []string `json:"exclude,omitempty"`
+	Overwrite              string   `json:"overwrite,omitempty"`
+	DryRun                 bool     `json:"dryRun,omitempty"`
+}
+
+// copySecretsREST handles REST POST /v1/api/secrets/copy
+func copySecretsREST(c echo.Context) error {
+	return transferREST(c, transferCopy)
+}
+
+// moveSecretsREST handles REST POST /v1/api/secrets/move
+func moveSecretsREST(c echo.Context) error {
+	return transferREST(c, transferMove)
+}
+
+func transferREST(c echo.Context, mode string) error {
+	var req TransferRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+
+	// Convert to JSON-LD TransferAction
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "TransferAction",
+		"toLocation": secretScope{
+			ProjectID:   req.DestinationProjectID,
+			Environment: req.DestinationEnvironment,
+			SecretPath:  req.DestinationSecretPath,
+		},
+		"keys":      req.Keys,
+		"include":   req.Include,
+		"exclude":   req.Exclude,
+		"mode":      mode,
+		"overwrite": req.Overwrite,
+		"dryRun":    req.DryRun,
+	}
+	addRESTTarget(action, req.ProjectID, req.Environment, req.SecretPath)
+
+	return callSemanticHandler(c, action)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/infisical/go-sdk/packages/models"
+)
+
+// transfer plans and executes a transfer between two scopes of a fake backend
+func transfer(t *testing.T, backend *fakeBackend, source, dest secretScope, opts transferOptions) ([]transferEntry, error) {
+	t.Helper()
+	if err := opts.validate(); err != nil {
+		t.Fatalf("Invalid transfer options: %v", err)
+	}
+	sourceSecrets, _ := backend.ListSecrets(source)
+	destSecrets, _ := backend.ListSecrets(dest)
+	entries, bySource, err := planTransfer(sourceSecrets, destSecrets, &opts)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	return entries, executeTransfer(backend, source, dest, opts.Mode, entries, bySource)
+}
+
+func TestTransfer_MoveSplitsFolder(t *testing.T) {
+	root := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	s3 := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/s3"}
+	backend := newFakeBackend()
+	backend.set(root, "S3_ACCESS_KEY", "AKIA")
+	backend.set(root, "S3_SECRET_KEY", "secret")
+	backend.set(root, "DB_PASSWORD", "pw")
+	backend.UpdateSecretMetadata(root, "S3_SECRET_KEY", []models.SecretMetadata{{Key: expiryMetadataKey, Value: "2026-01-01T00:00:00Z"}})
+
+	entries, err := transfer(t, backend, root, s3, transferOptions{ToLocation: &s3, keyFilter: keyFilter{Include: []string{"S3_*"}}, Mode: transferMove})
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Status != transferMoved || entries[1].Status != transferMoved {
+		t.Fatalf("Expected two moved keys, got %+v", entries)
+	}
+	if _, err := backend.RetrieveSecret(root, "S3_ACCESS_KEY", 0); err == nil {
+		t.Error("Moved keys must be deleted from the source")
+	}
+	if _, err := backend.RetrieveSecret(root, "DB_PASSWORD", 0); err != nil {
+		t.Error("Unselected keys must stay in the source")
+	}
+	moved, err := backend.RetrieveSecret(s3, "S3_SECRET_KEY", 0)
+	if err != nil || moved.SecretValue != "secret" {
+		t.Fatalf("Expected S3_SECRET_KEY in /s3, got %v", err)
+	}
+	if expiresAt, ok := secretExpiry(moved); !ok || expiresAt.Year() != 2026 {
+		t.Error("Expected metadata to be transferred")
+	}
+}
+
+func TestTransfer_MoveRollsBackOnPartialFailure(t *testing.T) {
+	root := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	db := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/db"}
+	backend := newFakeBackend()
+	backend.set(root, "DB_HOST", "db.internal")
+	backend.set(root, "DB_PASSWORD", "new-pw")
+	backend.set(root, "DB_USER", "app")
+	backend.set(db, "DB_PASSWORD", "old-pw")
+	backend.failKeys["DB_USER"] = true
+
+	entries, err := transfer(t, backend, root, db, transferOptions{ToLocation: &db, Mode: transferMove, Overwrite: overwriteAlways})
+	if err == nil {
+		t.Fatal("Expected the move to fail")
+	}
+	statuses := map[string]string{}
+	for _, e := range entries {
+		statuses[e.Name] = e.Status
+	}
+	if statuses["DB_HOST"] != transferRolledBack || statuses["DB_PASSWORD"] != transferRolledBack || statuses["DB_USER"] != transferFailed {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+	if _, err := backend.RetrieveSecret(db, "DB_HOST", 0); err == nil {
+		t.Error("Created destination keys must be deleted on rollback")
+	}
+	if restored, _ := backend.RetrieveSecret(db, "DB_PASSWORD", 0); restored.SecretValue != "old-pw" {
+		t.Error("Overwritten destination values must be restored on rollback")
+	}
+	for _, key := range []string{"DB_HOST", "DB_PASSWORD", "DB_USER"} {
+		if _, err := backend.RetrieveSecret(root, key, 0); err != nil {
+			t.Errorf("%s must remain in the source after a failed move", key)
+		}
+	}
+}
+
+func TestTransfer_CopyKeepsSourceAndSkipsConflicts(t *testing.T) {
+	dev := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	other := secretScope{ProjectID: "p2", Environment: "dev", SecretPath: "/shared"}
+	backend := newFakeBackend()
+	backend.set(dev, "A", "1")
+	backend.set(dev, "B", "2")
+	backend.set(dev, "C", "3")
+	backend.set(other, "B", "2")
+	backend.set(other, "C", "other")
+
+	entries, err := transfer(t, backend, dev, other, transferOptions{ToLocation: &other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]string{"A": {promoteActionCreate, transferCopied}, "B": {promoteActionNone, transferCopied}, "C": {promoteActionSkip, transferSkipped}}
+	for _, e := range entries {
+		if got := [2]string{e.Action, e.Status}; got != want[e.Name] {
+			t.Errorf("%s: expected %v, got %v", e.Name, want[e.Name], got)
+		}
+	}
+	if v, _ := backend.RetrieveSecret(other, "C", 0); v.SecretValue != "other" {
+		t.Error("Skip policy must not overwrite the destination")
+	}
+	if _, err := backend.RetrieveSecret(dev, "A", 0); err != nil {
+		t.Error("Copy must keep the source")
+	}
+}
+
+func TestTransferOptions_Validation(t *testing.T) {
+	if err := (&transferOptions{}).validate(); err == nil {
+		t.Error("Expected error without toLocation")
+	}
+	dest := &secretScope{SecretPath: "/db"}
+	if err := (&transferOptions{ToLocation: dest, Mode: "rename"}).validate(); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+	opts := &transferOptions{ToLocation: dest, keyFilter: keyFilter{Keys: []string{"MISSING"}}}
+	_ = opts.validate()
+	if _, _, err := planTransfer(nil, nil, opts); err == nil {
+		t.Error("Expected error for a key missing from the source")
+	}
+	if got := opts.destination(secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}); got.ProjectID != "p" || got.Environment != "prod" {
+		t.Errorf("Expected destination to default to the source project and environment, got %+v", got)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Value formats a validation policy can require
+const (
+	valueFormatURL      = "url"
+	valueFormatHostPort = "hostport"
+	valueFormatJSON     = "json"
+	valueFormatPEM      = "pem"
+)
+
+// ValidationConfig holds the value validation policies applied to every
+// create and update. A value must satisfy all policies matching its scope and key.
+type ValidationConfig struct {
+	Policies []ValidationPolicy `json:"policies,omitempty"`
+}
+
+// ValidationPolicy constrains the values of the selected keys
+type ValidationPolicy struct {
+	Name string `json:"name"`
+	// ProjectID and Environment restrict the policy to a project and environment (empty: any)
+	ProjectID   string `json:"projectId,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	// SecretPath restricts the policy to matching folders (path.Match syntax, empty: any)
+	SecretPath string `json:"secretPath,omitempty"`
+	keyFilter
+
+	// Pattern is a regular expression the whole value must match
+	Pattern string `json:"pattern,omitempty"`
+	// MinLength and MaxLength bound the value length in characters
+	MinLength int `json:"minLength,omitempty"`
+	MaxLength int `json:"maxLength,omitempty"`
+	// Format is url, hostport, json or pem
+	Format string `json:"format,omitempty"`
+	// Trimmed rejects values with leading or trailing whitespace
+	Trimmed bool `json:"trimmed,omitempty"`
+	// Forbidden lists values that must not be stored, such as placeholders
+	Forbidden []string `json:"forbidden,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// validate checks every policy and compiles the patterns
+func (v *ValidationConfig) validate() error {
+	names := map[string]bool{}
+	for i := range v.Policies {
+		p := &v.Policies[i]
+		if p.Name == "" {
+			return fmt.Errorf("validation.policies[%d]: name is required", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("validation policy %q is defined twice", p.Name)
+		}
+		names[p.Name] = true
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("validation policy %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *ValidationPolicy) validate() error {
+	if err := p.keyFilter.validate(); err != nil {
+		return err
+	}
+	if _, err := path.Match(p.SecretPath, ""); err != nil {
+		return fmt.Errorf("invalid secretPath pattern %q", p.SecretPath)
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(`^(?:` + p.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		p.pattern = re
+	}
+	if p.MinLength < 0 || p.MaxLength < 0 || (p.MaxLength > 0 && p.MinLength > p.MaxLength) {
+		return fmt.Errorf("minLength and maxLength must be non-negative with minLength <= maxLength")
+	}
+	switch p.Format {
+	case "", valueFormatURL, valueFormatHostPort, valueFormatJSON, valueFormatPEM:
+	default:
+		return fmt.Errorf("unknown format %q (want url, hostport, json or pem)", p.Format)
+	}
+	if p.pattern == nil && p.MinLength == 0 && p.MaxLength == 0 && p.Format == "" && !p.Trimmed && len(p.Forbidden) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	return nil
+}
+
+// applies reports whether the policy covers key in scope
+func (p *ValidationPolicy) applies(scope secretScope, key string) bool {
+	if p.ProjectID != "" && p.ProjectID != scope.ProjectID {
+		return false
+	}
+	if p.Environment != "" && p.Environment != scope.Environment {
+		return false
+	}
+	if p.SecretPath != "" {
+		if ok, _ := path.Match(p.SecretPath, scope.SecretPath); !ok {
+			return false
+		}
+	}
+	return p.matches(key)
+}
+
+// valueViolation is a rule a value failed. Messages never contain the value.
+type valueViolation struct {
+	Policy  string `json:"policy"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (v valueViolation) String() string {
+	return fmt.Sprintf("policy %q %s: %s", v.Policy, v.Rule, v.Message)
+}
+
+// check returns the rules of the policy that value fails
+func (p *ValidationPolicy) check(value string) []valueViolation {
+	var violations []valueViolation
+	fail := func(rule, format string, args ...interface{}) {
+		violations = append(violations, valueViolation{Policy: p.Name, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+	if p.Trimmed && strings.TrimSpace(value) != value {
+		fail("trimmed", "value has leading or trailing whitespace")
+	}
+	if n := utf8.RuneCountInString(value); n < p.MinLength {
+		fail("minLength", "value has %d characters, at least %d required", n, p.MinLength)
+	} else if p.MaxLength > 0 && n > p.MaxLength {
+		fail("maxLength", "value has %d characters, at most %d allowed", n, p.MaxLength)
+	}
+	if p.pattern != nil && !p.pattern.MatchString(value) {
+		fail("pattern", "value does not match %s", p.Pattern)
+	}
+	if p.Format != "" {
+		if err := checkValueFormat(p.Format, value); err != nil {
+			fail("format", "%v", err)
+		}
+	}
+	if containsString(p.Forbidden, value) {
+		fail("forbidden", "value is on the forbidden list")
+	}
+	return violations
+}
+
+// checkValueFormat checks that value is well-formed for format
+func checkValueFormat(format, value string) error {
+	switch format {
+	case valueFormatURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("value is not an absolute URL with scheme and host")
+		}
+	case valueFormatHostPort:
+		host, port, err := net.SplitHostPort(value)
+		if err != nil || host == "" {
+			return fmt.Errorf("value is not host:port")
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("port must be a number between 1 and 65535")
+		}
+	case valueFormatJSON:
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("value is not valid JSON")
+		}
+	case valueFormatPEM:
+		rest := []byte(value)
+		blocks := 0
+		for len(strings.TrimSpace(string(rest))) > 0 {
+			var block *pem.Block
+			if block, rest = pem.Decode(rest); block == nil {
+				return fmt.Errorf("value contains data outside PEM blocks")
+			}
+			blocks++
+		}
+		if blocks == 0 {
+			return fmt.Errorf("value contains no PEM block")
+		}
+	}
+	return nil
+}
+
+// violations returns the rules a value for key in scope fails across all matching policies
+func (v *ValidationConfig) violations(scope secretScope, key, value string) []valueViolation {
+	var violations []valueViolation
+	for i := range v.Policies {
+		if v.Policies[i].applies(scope, key) {
+			violations = append(violations, v.Policies[i].check(value)...)
+		}
+	}
+	return violations
+}
+
+// rejection describes the violations of one key, or returns "" if there are none
+func (v *ValidationConfig) rejection(scope secretScope, key, value string) string {
+	violations := v.violations(scope, key, value)
+	if len(violations) == 0 {
+		return ""
+	}
+	return "rejected by validation: " + joinViolations(violations)
+}
+
+func joinViolations(violations []valueViolation) string {
+	reasons := make([]string, len(violations))
+	for i, violation := range violations {
+		reasons[i] = violation.String()
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// valueRejectedError reports every key whose value failed validation
+type valueRejectedError struct {
+	Keys map[string][]valueViolation
+}
+
+func (e *valueRejectedError) Error() string {
+	keys := make([]string, 0, len(e.Keys))
+	for key := range e.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + joinViolations(e.Keys[key])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// check validates values by key and returns a *valueRejectedError if any fails
+func (v *ValidationConfig) check(scope secretScope, values map[string]string) error {
+	rejected := map[string][]valueViolation{}
+	for key, value := range values {
+		if violations := v.violations(scope, key, value); len(violations) > 0 {
+			rejected[key] = violations
+		}
+	}
+	if len(rejected) > 0 {
+		return &valueRejectedError{Keys: rejected}
+	}
+	return nil
+}
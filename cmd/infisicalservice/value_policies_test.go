@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testCertificatePEM = `-----BEGIN CERTIFICATE-----
+MIIBszCCAVmgAwIBAgIUQ0ZCMA==
+-----END CERTIFICATE-----
+`
+
+func testValidationConfig(t *testing.T) *ValidationConfig {
+	t.Helper()
+	cfg := &ValidationConfig{Policies: []ValidationPolicy{
+		{Name: "trimmed", Trimmed: true},
+		{Name: "urls", keyFilter: keyFilter{Include: []string{"*_URL"}}, Format: valueFormatURL, Forbidden: []string{"https://example.com"}},
+		{Name: "prod-tokens", Environment: "prod", SecretPath: "/s3*", keyFilter: keyFilter{Include: []string{"*_TOKEN"}}, MinLength: 16, Pattern: `[A-Za-z0-9]+`},
+	}}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestValidationPolicies_Rules(t *testing.T) {
+	cfg := testValidationConfig(t)
+	prodS3 := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/s3"}
+	dev := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/s3"}
+
+	cases := []struct {
+		scope secretScope
+		key   string
+		value string
+		rules []string
+	}{
+		{prodS3, "S3_URL", "https://s3.example.com", nil},
+		{prodS3, "S3_URL", "https://s3.example.com ", []string{"trimmed", "format"}},
+		{prodS3, "S3_URL", "s3.example.com", []string{"format"}},
+		{prodS3, "S3_URL", "https://example.com", []string{"forbidden"}},
+		{prodS3, "S3_TOKEN", "short", []string{"minLength"}},
+		{prodS3, "S3_TOKEN", "has-dashes-and-is-long", []string{"pattern"}},
+		{dev, "S3_TOKEN", "short", nil},
+		{prodS3, "PLAIN", " padded", []string{"trimmed"}},
+	}
+	for _, tc := range cases {
+		var rules []string
+		for _, v := range cfg.violations(tc.scope, tc.key, tc.value) {
+			rules = append(rules, v.Rule)
+			if strings.Contains(v.Message, tc.value) {
+				t.Errorf("%s: violation message must not contain the value: %s", tc.key, v.Message)
+			}
+		}
+		if strings.Join(rules, ",") != strings.Join(tc.rules, ",") {
+			t.Errorf("%s=%q in %s: expected %v, got %v", tc.key, tc.value, tc.scope, tc.rules, rules)
+		}
+	}
+
+	err := cfg.check(prodS3, map[string]string{"S3_URL": "nope", "OK": "fine"})
+	var rejected *valueRejectedError
+	if !errors.As(err, &rejected) || len(rejected.Keys) != 1 || !strings.Contains(err.Error(), `S3_URL: policy "urls" format`) {
+		t.Errorf("Expected S3_URL to be rejected with an explanation, got %v", err)
+	}
+}
+
+func TestValidationPolicies_Formats(t *testing.T) {
+	for _, tc := range []struct {
+		format, value string
+		ok            bool
+	}{
+		{valueFormatHostPort, "db.internal:5432", true},
+		{valueFormatHostPort, "db.internal", false},
+		{valueFormatHostPort, "db.internal:99999", false},
+		{valueFormatJSON, `{"a":1}`, true},
+		{valueFormatJSON, `{"a":`, false},
+		{valueFormatPEM, testCertificatePEM, true},
+		{valueFormatPEM, "not pem", false},
+		{valueFormatPEM, testCertificatePEM + "trailing", false},
+	} {
+		if err := checkValueFormat(tc.format, tc.value); (err == nil) != tc.ok {
+			t.Errorf("%s %q: expected ok=%v, got %v", tc.format, tc.value, tc.ok, err)
+		}
+	}
+}
+
+func TestValidationPolicies_ConfigErrors(t *testing.T) {
+	for name, policy := range map[string]ValidationPolicy{
+		"no rules":       {Name: "x"},
+		"bad pattern":    {Name: "x", Pattern: "("},
+		"unknown format": {Name: "x", Format: "yaml"},
+		"bounds":         {Name: "x", MinLength: 10, MaxLength: 5},
+	} {
+		if err := (&ValidationConfig{Policies: []ValidationPolicy{policy}}).validate(); err == nil {
+			t.Errorf("%s: expected the policy to be rejected", name)
+		}
+	}
+}
+
+func TestValidationPolicies_AppliedOnWrites(t *testing.T) {
+	saved := serviceConfig
+	defer func() { serviceConfig = saved }()
+	serviceConfig = &ServiceConfig{Validation: *testValidationConfig(t)}
+
+	scope := secretScope{ProjectID: "p", Environment: "dev", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "API_URL", "https://api.dev")
+
+	report, err := importSecrets(backend, scope, []secretEntry{
+		{Key: "API_URL", Value: "https://api.dev "},
+		{Key: "DB_URL", Value: "postgres://db"},
+	}, importModeUpsert, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report[0].Status != importInvalid || !strings.Contains(report[0].Reason, "trimmed") || report[1].Status != importCreated {
+		t.Errorf("Unexpected import report: %+v", report)
+	}
+	if current, _ := backend.RetrieveSecret(scope, "API_URL", 0); current.SecretValue != "https://api.dev" {
+		t.Error("A rejected value must not be written")
+	}
+
+	prod := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	entries := []promoteEntry{{Name: "API_URL", Action: promoteActionCreate}, {Name: "DB_URL", Action: promoteActionCreate}}
+	applyPromotion(backend, prod, entries, map[string]string{"API_URL": "not a url", "DB_URL": "postgres://db"})
+	if entries[0].Status != promoteFailed || entries[1].Status != promoteApplied {
+		t.Errorf("Expected only API_URL to be rejected, got %+v", entries)
+	}
+
+	generated := &generatedSecrets{Secrets: []generatedSecret{{Key: "CALLBACK_URL", Value: "generated"}}}
+	if _, err := storeGenerated(backend, scope, generated, true); err == nil {
+		t.Error("Expected a generated value failing a policy to be rejected")
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// pointInTimeSelector selects either a version number or the version current at a time
+type pointInTimeSelector struct {
+	Version int
+	AsOf    time.Time
+}
+
+// pointInTime validates the version selection options. It returns nil when the
+// action asks for current values.
+func (o *retrieveOptions) pointInTime() (*pointInTimeSelector, error) {
+	if (o.History || o.Version != 0 || o.AsOf != "") && (o.Object == nil || o.Object.Identifier == "") {
+		return nil, fmt.Errorf("object.identifier is required for version history and point-in-time retrieval")
+	}
+	if o.History && (o.Version != 0 || o.AsOf != "") {
+		return nil, fmt.Errorf("history cannot be combined with version or asOf")
+	}
+	if o.Version != 0 && o.AsOf != "" {
+		return nil, fmt.Errorf("version and asOf are mutually exclusive")
+	}
+	if o.Version < 0 {
+		return nil, fmt.Errorf("version must be positive")
+	}
+
+	switch {
+	case o.Version > 0:
+		return &pointInTimeSelector{Version: o.Version}, nil
+	case o.AsOf != "":
+		asOf, err := time.Parse(time.RFC3339, o.AsOf)
+		if err != nil {
+			return nil, fmt.Errorf("asOf must be an RFC 3339 timestamp: %w", err)
+		}
+		return &pointInTimeSelector{AsOf: asOf}, nil
+	}
+	return nil, nil
+}
+
+// secretVersionHistory lists the versions of a secret, oldest first. Values are
+// only included when explicitly requested.
+func secretVersionHistory(backend secretBackend, scope secretScope, key string, includeValues bool) ([]interface{}, error) {
+	current, err := backend.RetrieveSecret(scope, key, 0)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := backend.ListSecretVersions(current)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]interface{}, 0, len(versions))
+	for _, v := range versions {
+		entry := map[string]interface{}{
+			"name":      key,
+			"version":   v.Version,
+			"createdAt": v.CreatedAt.UTC().Format(time.RFC3339),
+			"current":   v.Version == current.Version,
+		}
+		if v.Actor != "" {
+			entry["actor"] = v.Actor
+		}
+		if includeValues {
+			entry["value"] = v.Value
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// resolveVersion finds the version number selected by sel
+func resolveVersion(backend secretBackend, scope secretScope, key string, sel pointInTimeSelector) (int, error) {
+	if sel.Version > 0 {
+		return sel.Version, nil
+	}
+
+	current, err := backend.RetrieveSecret(scope, key, 0)
+	if err != nil {
+		return 0, err
+	}
+	versions, err := backend.ListSecretVersions(current)
+	if err != nil {
+		return 0, err
+	}
+
+	// Versions are sorted oldest first; the last one created before asOf was current then
+	selected := 0
+	for _, v := range versions {
+		if !v.CreatedAt.After(sel.AsOf) {
+			selected = v.Version
+		}
+	}
+	if selected == 0 {
+		return 0, fmt.Errorf("secret did not exist at %s", sel.AsOf.UTC().Format(time.RFC3339))
+	}
+	return selected, nil
+}
+
+// secretAtPointInTime retrieves the value of a secret at a specific version or time
+func secretAtPointInTime(backend secretBackend, scope secretScope, key string, sel pointInTimeSelector) (map[string]interface{}, error) {
+	version, err := resolveVersion(backend, scope, key, sel)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := backend.RetrieveSecret(scope, key, version)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"name":    secret.SecretKey,
+		"value":   secret.SecretValue,
+		"version": secret.Version,
+	}, nil
+}
+
+// versionHistoryResult builds the result of a version history listing
+func versionHistoryResult(versions []interface{}, includeValues bool) *semantic.SemanticResult {
+	properties := []semantic.PropertyValueSpec{
+		{Type: "PropertyValue", Name: "name", ValueType: "Text", Description: "Secret key name"},
+		{Type: "PropertyValue", Name: "version", ValueType: "Integer", Description: "Version number"},
+		{Type: "PropertyValue", Name: "createdAt", ValueType: "DateTime", Description: "When the version was created"},
+		{Type: "PropertyValue", Name: "actor", ValueType: "Text", Description: "Who created the version, where available"},
+		{Type: "PropertyValue", Name: "current", ValueType: "Boolean", Description: "Whether this is the current version"},
+	}
+	if includeValues {
+		properties = append(properties, semantic.PropertyValueSpec{Type: "PropertyValue", Name: "value", ValueType: "Text", Description: "Secret value of the version"})
+	}
+	return &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value:  versions,
+		Schema: &semantic.ResultSchema{Type: "PropertyValueList", Properties: properties},
+	}
+}
+
+// secretVersionResult builds the result of a point-in-time retrieval
+func secretVersionResult(secrets []interface{}) *semantic.SemanticResult {
+	return &semantic.SemanticResult{
+		Type:   "Dataset",
+		Format: "application/json",
+		Value:  secrets,
+		Schema: &semantic.ResultSchema{
+			Type: "PropertyValueList",
+			Properties: []semantic.PropertyValueSpec{
+				{Type: "PropertyValue", Name: "name", ValueType: "Text", Description: "Secret key name"},
+				{Type: "PropertyValue", Name: "value", ValueType: "Text", Description: "Secret value"},
+				{Type: "PropertyValue", Name: "version", ValueType: "Integer", Description: "Version number"},
+			},
+		},
+	}
+}
+
+// getSecretVersionsREST handles REST GET /v1/api/secrets/:key/versions
+func getSecretVersionsREST(c echo.Context) error {
+	key := c.Param("key")
+	if key == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "key is required"})
+	}
+	includeValues, _ := strconv.ParseBool(c.QueryParam("includeValues"))
+
+	// Convert to JSON-LD RetrieveAction listing the version history
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"object": map[string]interface{}{
+			"@type":      "PropertyValue",
+			"identifier": key,
+		},
+		"history":       true,
+		"includeValues": includeValues,
+	}
+	addRESTTarget(action, c.QueryParam("projectId"), c.QueryParam("environment"), c.QueryParam("secretPath"))
+
+	return callSemanticHandler(c, action)
+}
+
+// getSecretVersionREST handles REST GET /v1/api/secrets/:key/versions/:version
+func getSecretVersionREST(c echo.Context) error {
+	key := c.Param("key")
+	if key == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "key is required"})
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "version must be a positive integer"})
+	}
+
+	// Convert to JSON-LD RetrieveAction for a specific version
+	action := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "RetrieveAction",
+		"object": map[string]interface{}{
+			"@type":      "PropertyValue",
+			"identifier": key,
+		},
+		"version": version,
+	}
+	addRESTTarget(action, c.QueryParam("projectId"), c.QueryParam("environment"), c.QueryParam("secretPath"))
+
+	return callSemanticHandler(c, action)
+}
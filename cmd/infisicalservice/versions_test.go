@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecretVersionHistory_OmitsValuesByDefault(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "BASEX_PASSWORD", "first")
+	backend.set(scope, "BASEX_PASSWORD", "second")
+
+	history, err := secretVersionHistory(backend, scope, "BASEX_PASSWORD", false)
+	if err != nil {
+		t.Fatalf("secretVersionHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 versions, got %d", len(history))
+	}
+	for _, entry := range history {
+		fields := entry.(map[string]interface{})
+		if _, ok := fields["value"]; ok {
+			t.Errorf("Version %v should not include a value by default", fields["version"])
+		}
+	}
+	if latest := history[1].(map[string]interface{}); latest["current"] != true || latest["version"] != 2 {
+		t.Errorf("Expected version 2 to be current, got %v", latest)
+	}
+
+	withValues, _ := secretVersionHistory(backend, scope, "BASEX_PASSWORD", true)
+	if got := withValues[0].(map[string]interface{})["value"]; got != "first" {
+		t.Errorf("Expected value of version 1 when requested, got %v", got)
+	}
+}
+
+func TestSecretAtPointInTime(t *testing.T) {
+	scope := secretScope{ProjectID: "p", Environment: "prod", SecretPath: "/"}
+	backend := newFakeBackend()
+	backend.set(scope, "API_TOKEN", "v1") // 01:00
+	backend.set(scope, "API_TOKEN", "v2") // 02:00
+	backend.set(scope, "API_TOKEN", "v3") // 03:00
+
+	byVersion, err := secretAtPointInTime(backend, scope, "API_TOKEN", pointInTimeSelector{Version: 2})
+	if err != nil || byVersion["value"] != "v2" {
+		t.Errorf("Expected v2 by version, got %v, %v", byVersion, err)
+	}
+
+	asOf := time.Date(2025, 1, 1, 2, 30, 0, 0, time.UTC)
+	byTime, err := secretAtPointInTime(backend, scope, "API_TOKEN", pointInTimeSelector{AsOf: asOf})
+	if err != nil || byTime["value"] != "v2" || byTime["version"] != 2 {
+		t.Errorf("Expected v2 as of %s, got %v, %v", asOf, byTime, err)
+	}
+
+	if _, err := secretAtPointInTime(backend, scope, "API_TOKEN", pointInTimeSelector{AsOf: asOf.Add(-24 * time.Hour)}); err == nil {
+		t.Error("Expected error for a time before the secret existed")
+	}
+}
+
+func TestRetrieveOptions_PointInTimeValidation(t *testing.T) {
+	object := &actionObject{Identifier: "KEY"}
+	invalid := []retrieveOptions{
+		{History: true},
+		{Version: 2},
+		{Object: object, Version: 2, AsOf: "2025-01-01T00:00:00Z"},
+		{Object: object, History: true, Version: 1},
+		{Object: object, AsOf: "yesterday"},
+		{Object: object, Version: -1},
+	}
+	for _, opts := range invalid {
+		if _, err := opts.pointInTime(); err == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+
+	if sel, err := (&retrieveOptions{}).pointInTime(); sel != nil || err != nil {
+		t.Errorf("Plain retrieval should select current values, got %v, %v", sel, err)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultWrapTTL applies when a wrap request does not set a TTL
+	defaultWrapTTL = 5 * time.Minute
+	// maxWrapTTL caps how long a wrapped response may live
+	maxWrapTTL = 24 * time.Hour
+	// maxWrapUses caps how often a wrapped response may be unwrapped
+	maxWrapUses = 10
+	// wrapTokenPrefix marks response wrapping tokens
+	wrapTokenPrefix = "wrp_"
+)
+
+// errWrapTokenInvalid is returned for unknown, expired or exhausted tokens
+var errWrapTokenInvalid = errors.New("wrapping token is invalid, expired or already used")
+
+// wrapOptions asks for a RetrieveAction result to be wrapped behind a single-use token
+type wrapOptions struct {
+	// TTL is a Go duration string such as "5m" (default 5m, max 24h)
+	TTL string `json:"ttl,omitempty"`
+	// MaxUses is how often the token may be unwrapped (default 1, max 10)
+	MaxUses int `json:"maxUses,omitempty"`
+}
+
+// wrapInfo describes a wrapping token without revealing the wrapped data
+type wrapInfo struct {
+	Token     string    `json:"token"`
+	Accessor  string    `json:"accessor"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	MaxUses   int       `json:"maxUses"`
+}
+
+// wrappedResponse is a stored result waiting to be unwrapped
+type wrappedResponse struct {
+	result    *semantic.SemanticResult
+	accessor  string
+	expiresAt time.Time
+	usesLeft  int
+}
+
+// wrapStore keeps wrapped results in memory, keyed by the SHA-256 of their token
+type wrapStore struct {
+	mu      sync.Mutex
+	entries map[string]*wrappedResponse
+	now     func() time.Time
+}
+
+// wrappedResponses is the service-wide store of wrapped results
+var wrappedResponses = newWrapStore()
+
+// newWrapStore creates an empty wrap store
+func newWrapStore() *wrapStore {
+	return &wrapStore{
+		entries: make(map[string]*wrappedResponse),
+		now:     time.Now,
+	}
+}
+
+// parse validates wrap options and applies defaults
+func (o *wrapOptions) parse() (time.Duration, int, error) {
+	ttl := defaultWrapTTL
+	if o.TTL != "" {
+		d, err := time.ParseDuration(o.TTL)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid wrap.ttl %q: %w", o.TTL, err)
+		}
+		if d <= 0 || d > maxWrapTTL {
+			return 0, 0, fmt.Errorf("wrap.ttl must be between 1s and %s", maxWrapTTL)
+		}
+		ttl = d
+	}
+
+	uses := o.MaxUses
+	if uses == 0 {
+		uses = 1
+	}
+	if uses < 1 || uses > maxWrapUses {
+		return 0, 0, fmt.Errorf("wrap.maxUses must be between 1 and %d", maxWrapUses)
+	}
+	return ttl, uses, nil
+}
+
+// Wrap stores a result and returns the token needed to unwrap it
+func (s *wrapStore) Wrap(result *semantic.SemanticResult, ttl time.Duration, maxUses int) (wrapInfo, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return wrapInfo{}, fmt.Errorf("failed to generate wrapping token: %w", err)
+	}
+	token := wrapTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	hash := hashWrapToken(token)
+
+	entry := &wrappedResponse{
+		result:    result,
+		accessor:  hash[:16],
+		expiresAt: s.now().Add(ttl),
+		usesLeft:  maxUses,
+	}
+
+	s.mu.Lock()
+	s.entries[hash] = entry
+	s.mu.Unlock()
+
+	auditEvent("wrap.created", map[string]interface{}{
+		"accessor":  entry.accessor,
+		"expiresAt": entry.expiresAt.UTC().Format(time.RFC3339),
+		"maxUses":   maxUses,
+	})
+
+	return wrapInfo{
+		Token:     token,
+		Accessor:  entry.accessor,
+		ExpiresAt: entry.expiresAt,
+		MaxUses:   maxUses,
+	}, nil
+}
+
+// Unwrap returns the wrapped result and consumes one use of the token
+func (s *wrapStore) Unwrap(token string) (*semantic.SemanticResult, error) {
+	if !strings.HasPrefix(token, wrapTokenPrefix) {
+		return nil, errWrapTokenInvalid
+	}
+	hash := hashWrapToken(token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[hash]
+	if !ok {
+		return nil, errWrapTokenInvalid
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(s.entries, hash)
+		auditEvent("wrap.expired", map[string]interface{}{"accessor": entry.accessor})
+		return nil, errWrapTokenInvalid
+	}
+
+	entry.usesLeft--
+	if entry.usesLeft <= 0 {
+		delete(s.entries, hash)
+	}
+	auditEvent("wrap.unwrapped", map[string]interface{}{
+		"accessor": entry.accessor,
+		"usesLeft": entry.usesLeft,
+	})
+	return entry.result, nil
+}
+
+// Sweep removes expired entries, auditing each expiry
+func (s *wrapStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for hash, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, hash)
+			auditEvent("wrap.expired", map[string]interface{}{"accessor": entry.accessor})
+		}
+	}
+}
+
+// RunExpiry sweeps expired entries at the given interval until stop is closed
+func (s *wrapStore) RunExpiry(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Sweep()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// hashWrapToken derives the storage key of a token so raw tokens are never kept
+func hashWrapToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// wrapResult replaces an action result with a wrapping token result
+func wrapResult(result *semantic.SemanticResult, opts *wrapOptions) (*semantic.SemanticResult, error) {
+	ttl, uses, err := opts.parse()
+	if err != nil {
+		return nil, err
+	}
+	info, err := wrappedResponses.Wrap(result, ttl, uses)
+	if err != nil {
+		return nil, err
+	}
+	return &semantic.SemanticResult{
+		Type:   "WrappedResponse",
+		Format: "application/json",
+		Value:  info,
+	}, nil
+}
+
+// UnwrapRequest is the body of POST /v1/api/unwrap
+type UnwrapRequest struct {
+	Token string `json:"token"`
+}
+
+// unwrapREST handles REST POST /v1/api/unwrap
+func unwrapREST(c echo.Context) error {
+	var req UnwrapRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Invalid request: %v", err)})
+	}
+	if req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "token is required"})
+	}
+
+	result, err := wrappedResponses.Unwrap(req.Token)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
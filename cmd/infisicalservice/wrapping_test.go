@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// captureAudit records audit event names for the duration of the test
+func captureAudit(t *testing.T) *[]string {
+	t.Helper()
+	var events []string
+	original := auditEvent
+	auditEvent = func(event string, fields map[string]interface{}) {
+		events = append(events, event)
+	}
+	t.Cleanup(func() { auditEvent = original })
+	return &events
+}
+
+func TestWrapStore_SingleUse(t *testing.T) {
+	events := captureAudit(t)
+	store := newWrapStore()
+	result := &semantic.SemanticResult{Type: "Dataset", Value: []interface{}{map[string]string{"name": "K", "value": "v"}}}
+
+	info, err := store.Wrap(result, time.Minute, 1)
+	if err != nil {
+		t.Fatalf("Wrap failed: %v", err)
+	}
+	if !strings.HasPrefix(info.Token, wrapTokenPrefix) {
+		t.Errorf("Unexpected token format %q", info.Token)
+	}
+
+	got, err := store.Unwrap(info.Token)
+	if err != nil || got != result {
+		t.Fatalf("First unwrap should return the result, got %v, %v", got, err)
+	}
+	if _, err := store.Unwrap(info.Token); err != errWrapTokenInvalid {
+		t.Errorf("Second unwrap should fail, got %v", err)
+	}
+
+	want := []string{"wrap.created", "wrap.unwrapped"}
+	if strings.Join(*events, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected audit events %v, got %v", want, *events)
+	}
+}
+
+func TestWrapStore_Expiry(t *testing.T) {
+	events := captureAudit(t)
+	store := newWrapStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	expiring, _ := store.Wrap(&semantic.SemanticResult{}, time.Minute, 3)
+	swept, _ := store.Wrap(&semantic.SemanticResult{}, time.Minute, 1)
+
+	now = now.Add(2 * time.Minute)
+	if _, err := store.Unwrap(expiring.Token); err != errWrapTokenInvalid {
+		t.Errorf("Expired token should be rejected, got %v", err)
+	}
+	store.Sweep()
+	if _, err := store.Unwrap(swept.Token); err != errWrapTokenInvalid {
+		t.Errorf("Swept token should be rejected, got %v", err)
+	}
+
+	expired := 0
+	for _, e := range *events {
+		if e == "wrap.expired" {
+			expired++
+		}
+	}
+	if expired != 2 {
+		t.Errorf("Expected 2 wrap.expired audit events, got %d (%v)", expired, *events)
+	}
+}
+
+func TestWrapOptions_Validation(t *testing.T) {
+	invalid := []wrapOptions{
+		{TTL: "soon"},
+		{TTL: "-1m"},
+		{TTL: "48h"},
+		{MaxUses: -1},
+		{MaxUses: maxWrapUses + 1},
+	}
+	for _, opts := range invalid {
+		if _, _, err := opts.parse(); err == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+
+	ttl, uses, err := (&wrapOptions{}).parse()
+	if err != nil || ttl != defaultWrapTTL || uses != 1 {
+		t.Errorf("Unexpected defaults: %v, %d, %v", ttl, uses, err)
+	}
+}
+
+func TestUnwrapEndpoint(t *testing.T) {
+	captureAudit(t)
+	info, err := wrappedResponses.Wrap(&semantic.SemanticResult{Type: "Dataset"}, time.Minute, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.POST("/v1/api/unwrap", unwrapREST)
+
+	unwrap := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/api/unwrap", bytes.NewReader([]byte(`{"token":"`+info.Token+`"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := unwrap(); code != http.StatusOK {
+		t.Errorf("Expected status %d on first unwrap, got %d", http.StatusOK, code)
+	}
+	if code := unwrap(); code != http.StatusNotFound {
+		t.Errorf("Expected status %d on second unwrap, got %d", http.StatusNotFound, code)
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"eve.evalgo.org/semantic"
+	"github.com/labstack/echo/v4"
+)
+
+// writeOptions holds the properties shared by CreateAction and UpdateAction
+type writeOptions struct {
+	Object   *actionObject    `json:"object,omitempty"`
+	Generate *generatorPolicy `json:"generate,omitempty"`
+	// ReturnValue includes generated values in the result (metadata only by default)
+	ReturnValue bool `json:"returnValue,omitempty"`
+	// ExpiresAt records an expiry date in the secret metadata ("none" removes it)
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// createOptions holds the CreateAction properties
+type createOptions struct {
+	writeOptions
+	Import       *importOptions       `json:"import,omitempty"`
+	Folder       *folderOptions       `json:"folder,omitempty"`
+	SecretImport *secretImportOptions `json:"secretImport,omitempty"`
+}
+
+// updateOptions holds the UpdateAction properties
+type updateOptions struct {
+	writeOptions
+	Rollback  *rollbackOptions  `json:"rollback,omitempty"`
+	Rotate    *rotateOptions    `json:"rotate,omitempty"`
+	Promote   *promoteOptions   `json:"promote,omitempty"`
+	Replicate *replicateOptions `json:"replicate,omitempty"`
+	Folder    *folderOptions    `json:"folder,omitempty"`
+}
+
+// deleteOptions holds the DeleteAction properties
+type deleteOptions struct {
+	Object       *actionObject        `json:"object,omitempty"`
+	Folder       *folderOptions       `json:"folder,omitempty"`
+	SecretImport *secretImportOptions `json:"secretImport,omitempty"`
+}
+
+// handleCreateAction handles secret creation, with an explicit or generated value, bulk imports, folder creation and secret imports
+func handleCreateAction(c echo.Context, actionInterface interface{}) error {
+	action, ok := actionInterface.(*semantic.SemanticAction)
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid action type")
+	}
+	var opts createOptions
+	if err := decodeActionProperties(c, &opts); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid CreateAction", err)
+	}
+	if opts.Import != nil {
+		return handleImport(c, action, opts.Import)
+	}
+	if opts.Folder != nil {
+		return handleCreateFolder(c, action, opts.Folder)
+	}
+	if opts.SecretImport != nil {
+		return handleCreateSecretImport(c, action, opts.SecretImport)
+	}
+	return handleWrite(c, action, opts.writeOptions, true)
+}
+
+// handleUpdateAction handles secret updates, rollbacks, on-demand rotations, promotions, replications and folder renames
+func handleUpdateAction(c echo.Context, actionInterface interface{}) error {
+	action, ok := actionInterface.(*semantic.SemanticAction)
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid action type")
+	}
+	var opts updateOptions
+	if err := decodeActionProperties(c, &opts); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid UpdateAction", err)
+	}
+	if opts.Rollback != nil {
+		return handleRollback(c, action, opts)
+	}
+	if opts.Rotate != nil {
+		return handleRotateNow(c, action, opts.Rotate)
+	}
+	if opts.Promote != nil {
+		return handlePromote(c, action, opts.Promote)
+	}
+	if opts.Replicate != nil {
+		return handleReplicateNow(c, action, opts.Replicate)
+	}
+	if opts.Folder != nil {
+		return handleRenameFolder(c, action, opts.Folder)
+	}
+	return handleWrite(c, action, opts.writeOptions, false)
+}
+
+// handleDeleteAction deletes object.identifier, a folder or a secret import in the target path
+func handleDeleteAction(c echo.Context, actionInterface interface{}) error {
+	action, ok := actionInterface.(*semantic.SemanticAction)
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid action type")
+	}
+	var opts deleteOptions
+	if err := decodeActionProperties(c, &opts); err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid DeleteAction", err)
+	}
+	if opts.Folder != nil {
+		return handleDeleteFolder(c, action, opts.Folder)
+	}
+	if opts.SecretImport != nil {
+		return handleDeleteSecretImport(c, action, opts.SecretImport)
+	}
+	if opts.Object == nil || opts.Object.Identifier == "" {
+		return semantic.ReturnActionError(c, action, "Invalid DeleteAction", fmt.Errorf("object.identifier or folder is required"))
+	}
+
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+	key := opts.Object.Identifier
+	if err := backend.DeleteSecret(scope, key); err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to delete secret", err)
+	}
+	log.Printf("Deleted secret %s (%s)", redaction.KeyName(key), scope)
+	auditEvent("secret.deleted", map[string]interface{}{
+		"projectId":   scope.ProjectID,
+		"environment": scope.Environment,
+		"secretPath":  scope.SecretPath,
+		"key":         redaction.KeyName(key),
+	})
+
+	action.Result = &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value:  map[string]interface{}{"name": key, "deleted": true},
+	}
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// handleWrite creates or updates object.identifier with an explicit or generated
+// value and/or sets its expiry. An update may change only the expiry.
+func handleWrite(c echo.Context, action *semantic.SemanticAction, opts writeOptions, create bool) error {
+	invalid := "Invalid UpdateAction"
+	if create {
+		invalid = "Invalid CreateAction"
+	}
+	if opts.Object == nil || opts.Object.Identifier == "" {
+		return semantic.ReturnActionError(c, action, invalid, fmt.Errorf("object.identifier is required"))
+	}
+	expiresAt, err := parseExpiryOption(opts.ExpiresAt)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, invalid, err)
+	}
+	switch {
+	case opts.Generate != nil && opts.Object.Value != "":
+		return semantic.ReturnActionError(c, action, "Invalid generate options", fmt.Errorf("object.value and generate are mutually exclusive"))
+	case opts.Generate != nil:
+		if err := opts.Generate.validate(); err != nil {
+			return semantic.ReturnActionError(c, action, "Invalid generate options", err)
+		}
+	case opts.Object.Value == "" && (create || opts.ExpiresAt == ""):
+		return semantic.ReturnActionError(c, action, invalid, fmt.Errorf("object.value or generate is required"))
+	}
+
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+
+	key := opts.Object.Identifier
+	var result *semantic.SemanticResult
+	switch {
+	case opts.Generate != nil:
+		generated, err := opts.Generate.generate(key)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to generate secret", err)
+		}
+		stored, err := storeGenerated(backend, scope, generated, create)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to store generated secret", err)
+		}
+		log.Printf("Generated %s secret %s (%s)", opts.Generate.Type, redaction.KeyName(key), scope)
+		auditEvent("secret.generated", map[string]interface{}{
+			"projectId":   scope.ProjectID,
+			"environment": scope.Environment,
+			"secretPath":  scope.SecretPath,
+			"key":         redaction.KeyName(key),
+			"generator":   opts.Generate.Type,
+		})
+		result = generatedResult(generated, stored, opts.ReturnValue)
+
+	case opts.Object.Value != "":
+		if err := serviceConfig.Validation.check(scope, map[string]string{key: opts.Object.Value}); err != nil {
+			return semantic.ReturnActionError(c, action, "Value rejected by validation policy", err)
+		}
+		write, failure, verb := backend.UpdateSecret, "Failed to update secret", "Updated"
+		if create {
+			write, failure, verb = backend.CreateSecret, "Failed to create secret", "Created"
+		}
+		secret, err := write(scope, key, opts.Object.Value)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, failure, err)
+		}
+		log.Printf("%s secret %s (%s)", verb, redaction.KeyName(secret.SecretKey), scope)
+		result = secretMetadataResult(secret.SecretKey, secret.Version)
+	}
+
+	if opts.ExpiresAt != "" {
+		secret, err := setSecretExpiry(backend, scope, key, expiresAt)
+		if err != nil {
+			return semantic.ReturnActionError(c, action, "Failed to set secret expiry", err)
+		}
+		if result == nil {
+			result = secretMetadataResult(secret.SecretKey, secret.Version)
+		}
+		result.Value.(map[string]interface{})["expiresAt"] = formatExpiry(expiresAt)
+	}
+
+	action.Result = result
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// handleRollback restores one key, or every key in the target path, to a prior version
+func handleRollback(c echo.Context, action *semantic.SemanticAction, opts updateOptions) error {
+	sel, err := opts.Rollback.selector()
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Invalid rollback options", err)
+	}
+
+	backend, scope, done, err := actionBackend(c, action)
+	if done {
+		return err
+	}
+
+	var keys []string
+	if opts.Object != nil && opts.Object.Identifier != "" {
+		keys = []string{opts.Object.Identifier}
+	}
+	entries, err := rollbackSecrets(backend, scope, keys, sel, opts.Rollback.DryRun)
+	if err != nil {
+		return semantic.ReturnActionError(c, action, "Failed to roll back secrets", err)
+	}
+
+	changed := 0
+	for _, e := range entries {
+		if e.Status == rollbackChanged {
+			changed++
+		}
+	}
+	log.Printf("Rollback of %d keys (%s, dryRun=%v): %d changed", len(entries), scope, opts.Rollback.DryRun, changed)
+	if !opts.Rollback.DryRun {
+		auditEvent("secret.rollback", map[string]interface{}{
+			"projectId":   scope.ProjectID,
+			"environment": scope.Environment,
+			"secretPath":  scope.SecretPath,
+			"keys":        len(entries),
+			"changed":     changed,
+		})
+	}
+
+	action.Result = rollbackResult(entries, opts.Rollback.DryRun)
+	semantic.SetSuccessOnAction(action)
+	return c.JSON(http.StatusOK, action)
+}
+
+// storeGenerated writes generated secrets and returns their new versions. The
+// primary key is created or updated as requested; companion keys are updated
+// when they exist and created otherwise. Nothing is written unless every
+// value passes the validation policies, and if a write fails the keys written
+// before it are restored so a keypair or certificate never ends up half-replaced.
+func storeGenerated(backend secretBackend, scope secretScope, generated *generatedSecrets, create bool) ([]int, error) {
+	values := make(map[string]string, len(generated.Secrets))
+	for _, s := range generated.Secrets {
+		values[s.Key] = s.Value
+	}
+	if err := serviceConfig.Validation.check(scope, values); err != nil {
+		return nil, err
+	}
+
+	// previous holds the value of each existing key, nil for keys to create
+	previous := make([]*string, len(generated.Secrets))
+	for i, s := range generated.Secrets {
+		if i == 0 && create {
+			continue
+		}
+		current, err := backend.RetrieveSecret(scope, s.Key, 0)
+		switch {
+		case err == nil:
+			previous[i] = &current.SecretValue
+		case i == 0 || !errors.Is(err, errSecretNotFound):
+			return nil, fmt.Errorf("%s: %w", redaction.KeyName(s.Key), err)
+		}
+	}
+
+	versions := make([]int, len(generated.Secrets))
+	for i, s := range generated.Secrets {
+		write := backend.UpdateSecret
+		if previous[i] == nil {
+			write = backend.CreateSecret
+		}
+		secret, err := write(scope, s.Key, s.Value)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", redaction.KeyName(s.Key), err)
+			if undoErr := restoreGenerated(backend, scope, generated.Secrets[:i], previous); undoErr != nil {
+				return nil, fmt.Errorf("%w; restoring the keys written before it failed: %v", err, undoErr)
+			}
+			return nil, err
+		}
+		versions[i] = secret.Version
+	}
+	return versions, nil
+}
+
+// restoreGenerated undoes the writes of storeGenerated in reverse order:
+// keys that existed get their previous value back, created keys are deleted
+func restoreGenerated(backend secretBackend, scope secretScope, written []generatedSecret, previous []*string) error {
+	var failed []string
+	for i := len(written) - 1; i >= 0; i-- {
+		key := written[i].Key
+		var err error
+		if previous[i] == nil {
+			err = backend.DeleteSecret(scope, key)
+		} else {
+			_, err = backend.UpdateSecret(scope, key, *previous[i])
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", redaction.KeyName(key), err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// generatedResult describes generated secrets. Values are only included when
+// explicitly requested with returnValue.
+func generatedResult(generated *generatedSecrets, versions []int, returnValue bool) *semantic.SemanticResult {
+	secrets := make([]map[string]interface{}, len(generated.Secrets))
+	for i, s := range generated.Secrets {
+		entry := map[string]interface{}{"name": s.Key, "version": versions[i]}
+		if returnValue {
+			entry["value"] = s.Value
+		}
+		secrets[i] = entry
+	}
+	return &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"name":      generated.Secrets[0].Key,
+			"version":   versions[0],
+			"generated": generated.Metadata,
+			"secrets":   secrets,
+		},
+	}
+}
+
+// secretMetadataResult describes a written secret without its value
+func secretMetadataResult(key string, version int) *semantic.SemanticResult {
+	return &semantic.SemanticResult{
+		Type:   "PropertyValue",
+		Format: "application/json",
+		Value: map[string]interface{}{
+			"name":    key,
+			"version": version,
+		},
+	}
+}
@@ -6,6 +6,7 @@ require (
 	eve.evalgo.org v0.0.50
 	github.com/infisical/go-sdk v0.5.100
 	github.com/labstack/echo/v4 v4.13.4
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,292 @@
+// Package seal encrypts secret values to a recipient public key so they can
+// travel through proxies, logs and workflow state without being readable.
+//
+// Two recipient key types are supported:
+//
+//   - X25519: an ephemeral key agreement (age-style) whose shared secret is
+//     expanded with HKDF-SHA256 into an AES-256-GCM key.
+//   - RSA: a random AES-256-GCM key wrapped with RSA-OAEP (SHA-256).
+//
+// Consumers decrypt envelopes with Open or OpenString using the matching private key.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// AlgorithmX25519 identifies envelopes sealed via X25519 key agreement
+	AlgorithmX25519 = "X25519-HKDF-SHA256-A256GCM"
+	// AlgorithmRSAOAEP identifies envelopes whose content key is wrapped with RSA-OAEP
+	AlgorithmRSAOAEP = "RSA-OAEP-256-A256GCM"
+
+	// info binds derived keys and wrapped keys to this envelope format
+	info = "infisicalservice seal v1"
+)
+
+// ErrDecrypt is returned when an envelope cannot be opened with the given key
+var ErrDecrypt = errors.New("seal: decryption failed")
+
+// Envelope is a sealed value. All binary fields are base64 (standard encoding).
+type Envelope struct {
+	Algorithm    string `json:"alg"`
+	EphemeralKey string `json:"epk,omitempty"`
+	EncryptedKey string `json:"ek,omitempty"`
+	Nonce        string `json:"nonce"`
+	Ciphertext   string `json:"ciphertext"`
+}
+
+// ParsePublicKey parses a recipient public key. It accepts a PEM encoded
+// PKIX public key (RSA or X25519), or a base64 encoded raw 32-byte X25519 key.
+func ParsePublicKey(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("seal: invalid public key: %w", err)
+		}
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			if k.N.BitLen() < 2048 {
+				return nil, errors.New("seal: RSA public keys must be at least 2048 bits")
+			}
+			return k, nil
+		case *ecdh.PublicKey:
+			if k.Curve() != ecdh.X25519() {
+				return nil, errors.New("seal: only X25519 ECDH keys are supported")
+			}
+			return k, nil
+		default:
+			return nil, fmt.Errorf("seal: unsupported public key type %T", key)
+		}
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("seal: public key must be PEM or base64 encoded")
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("seal: invalid X25519 public key: %w", err)
+	}
+	return key, nil
+}
+
+// ParsePrivateKey parses a recipient private key. It accepts a PEM encoded
+// PKCS#8 or PKCS#1 private key, or a base64 encoded raw 32-byte X25519 key.
+func ParsePrivateKey(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return key, nil
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("seal: invalid private key: %w", err)
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey, *ecdh.PrivateKey:
+			return k, nil
+		default:
+			return nil, fmt.Errorf("seal: unsupported private key type %T", key)
+		}
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("seal: private key must be PEM or base64 encoded")
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("seal: invalid X25519 private key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext to the given public key (*ecdh.PublicKey or *rsa.PublicKey)
+func Seal(publicKey interface{}, plaintext []byte) (*Envelope, error) {
+	switch pub := publicKey.(type) {
+	case *ecdh.PublicKey:
+		return sealX25519(pub, plaintext)
+	case *rsa.PublicKey:
+		return sealRSA(pub, plaintext)
+	default:
+		return nil, fmt.Errorf("seal: unsupported public key type %T", publicKey)
+	}
+}
+
+// SealString is a convenience wrapper around Seal for string values
+func SealString(publicKey interface{}, value string) (*Envelope, error) {
+	return Seal(publicKey, []byte(value))
+}
+
+// Open decrypts an envelope with the given private key (*ecdh.PrivateKey or *rsa.PrivateKey)
+func Open(privateKey interface{}, env *Envelope) ([]byte, error) {
+	if env == nil {
+		return nil, errors.New("seal: nil envelope")
+	}
+
+	var key []byte
+	var err error
+	switch env.Algorithm {
+	case AlgorithmX25519:
+		priv, ok := privateKey.(*ecdh.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("seal: %s envelope requires an X25519 private key", env.Algorithm)
+		}
+		key, err = openX25519Key(priv, env)
+	case AlgorithmRSAOAEP:
+		priv, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("seal: %s envelope requires an RSA private key", env.Algorithm)
+		}
+		key, err = openRSAKey(priv, env)
+	default:
+		return nil, fmt.Errorf("seal: unsupported algorithm %q", env.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(env.Algorithm))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// OpenString is a convenience wrapper around Open for string values
+func OpenString(privateKey interface{}, env *Envelope) (string, error) {
+	plaintext, err := Open(privateKey, env)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func sealX25519(pub *ecdh.PublicKey, plaintext []byte) (*Envelope, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("seal: failed to generate ephemeral key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(pub)
+	if err != nil {
+		return nil, fmt.Errorf("seal: key agreement failed: %w", err)
+	}
+	epk := ephemeral.PublicKey().Bytes()
+	key, err := deriveKey(shared, epk, pub.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Envelope{
+		Algorithm:    AlgorithmX25519,
+		EphemeralKey: base64.StdEncoding.EncodeToString(epk),
+	}
+	return env, encrypt(env, key, plaintext)
+}
+
+func sealRSA(pub *rsa.PublicKey, plaintext []byte) (*Envelope, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("seal: failed to generate content key: %w", err)
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, key, []byte(info))
+	if err != nil {
+		return nil, fmt.Errorf("seal: failed to wrap content key: %w", err)
+	}
+
+	env := &Envelope{
+		Algorithm:    AlgorithmRSAOAEP,
+		EncryptedKey: base64.StdEncoding.EncodeToString(wrapped),
+	}
+	return env, encrypt(env, key, plaintext)
+}
+
+func openX25519Key(priv *ecdh.PrivateKey, env *Envelope) ([]byte, error) {
+	epk, err := base64.StdEncoding.DecodeString(env.EphemeralKey)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(epk)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	shared, err := priv.ECDH(ephemeral)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return deriveKey(shared, epk, priv.PublicKey().Bytes())
+}
+
+func openRSAKey(priv *rsa.PrivateKey, env *Envelope) ([]byte, error) {
+	wrapped, err := base64.StdEncoding.DecodeString(env.EncryptedKey)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), nil, priv, wrapped, []byte(info))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return key, nil
+}
+
+// deriveKey expands an X25519 shared secret into an AES-256 key bound to both public keys
+func deriveKey(shared, ephemeralPublic, recipientPublic []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeralPublic...), recipientPublic...)
+	key, err := hkdf.Key(sha256.New, shared, salt, info, 32)
+	if err != nil {
+		return nil, fmt.Errorf("seal: key derivation failed: %w", err)
+	}
+	return key, nil
+}
+
+// encrypt fills the nonce and ciphertext of env using AES-256-GCM
+func encrypt(env *Envelope, key, plaintext []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("seal: failed to generate nonce: %w", err)
+	}
+	env.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	env.Ciphertext = base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, []byte(env.Algorithm)))
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("seal: invalid content key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,106 @@
+package seal
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen_X25519(t *testing.T) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Raw base64 key as a consumer would pass it in a RetrieveAction
+	pub, err := ParsePublicKey(base64.StdEncoding.EncodeToString(priv.PublicKey().Bytes()))
+	if err != nil {
+		t.Fatalf("ParsePublicKey failed: %v", err)
+	}
+
+	env, err := SealString(pub, "s3cr3t")
+	if err != nil {
+		t.Fatalf("SealString failed: %v", err)
+	}
+	if env.Algorithm != AlgorithmX25519 || env.EphemeralKey == "" {
+		t.Errorf("Unexpected envelope: %+v", env)
+	}
+
+	got, err := OpenString(priv, env)
+	if err != nil {
+		t.Fatalf("OpenString failed: %v", err)
+	}
+	if got != "s3cr3t" {
+		t.Errorf("Expected 's3cr3t', got %q", got)
+	}
+}
+
+func TestSealOpen_RSA(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	pub, err := ParsePublicKey(pemKey)
+	if err != nil {
+		t.Fatalf("ParsePublicKey failed: %v", err)
+	}
+
+	env, err := Seal(pub, []byte("BdQ9Gr4QVBo4YxgY4ov5n1DZA6GXBeIBQmfqD6JO"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if env.Algorithm != AlgorithmRSAOAEP || env.EncryptedKey == "" {
+		t.Errorf("Unexpected envelope: %+v", env)
+	}
+
+	privPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	parsed, err := ParsePrivateKey(privPEM)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey failed: %v", err)
+	}
+	got, err := OpenString(parsed, env)
+	if err != nil {
+		t.Fatalf("OpenString failed: %v", err)
+	}
+	if got != "BdQ9Gr4QVBo4YxgY4ov5n1DZA6GXBeIBQmfqD6JO" {
+		t.Errorf("Unexpected plaintext %q", got)
+	}
+}
+
+func TestOpen_RejectsTamperedAndWrongKey(t *testing.T) {
+	priv, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	other, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	env, err := SealString(priv.PublicKey(), "value")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(other, env); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt with wrong key, got %v", err)
+	}
+
+	tampered := *env
+	ciphertext, _ := base64.StdEncoding.DecodeString(env.Ciphertext)
+	ciphertext[0] ^= 0xff
+	tampered.Ciphertext = base64.StdEncoding.EncodeToString(ciphertext)
+	if _, err := Open(priv, &tampered); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for tampered ciphertext, got %v", err)
+	}
+}
+
+func TestParsePublicKey_Invalid(t *testing.T) {
+	for _, key := range []string{"", "not-base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParsePublicKey(key); err == nil {
+			t.Errorf("Expected error for key %q", key)
+		}
+	}
+}